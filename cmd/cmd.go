@@ -1,19 +1,27 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/kevwargo/go-pst/internal/benchmark"
 	"github.com/kevwargo/go-pst/internal/pst/tree"
 	"github.com/kevwargo/go-pst/internal/pst/tui"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func Execute() error {
 	var cfg config
+	cfg.tree.Ancestors = tree.DepthAll
+	cfg.tree.Descendants = tree.DepthAll
+	cfg.tree.Sort = tree.SortDefault
 
 	cmd := &cobra.Command{
 		Use:           "pst",
+		Args:          cobra.ArbitraryArgs,
 		SilenceErrors: true,
 		SilenceUsage:  true,
 		RunE: func(_ *cobra.Command, args []string) error {
@@ -21,22 +29,79 @@ func Execute() error {
 		},
 	}
 
-	fs := cmd.Flags()
-	fs.BoolVarP(&cfg.tree.PCfg.Workdir, "workdir", "w", false, "")
-	fs.BoolVarP(&cfg.tree.PCfg.UGID, "uid-gid", "u", false, "")
-	fs.BoolVarP(&cfg.tree.PCfg.NamespacePID, "namespace-pid", "N", false, "")
-	fs.BoolVarP(&cfg.tree.PCfg.Threads, "threads", "T", false, "")
-	fs.BoolVarP(&cfg.tree.PCfg.FDs, "file-descriptors", "F", false, "")
-	fs.BoolVarP(&cfg.tree.ShowDead, "show-dead", "D", false, "")
-	fs.BoolVarP(&cfg.tree.FullMatch, "full-match", "f", false, "")
+	diffCmd := &cobra.Command{
+		Use:  "diff OLD NEW [PATTERN...]",
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return executeDiff(&cfg, args)
+		},
+	}
+	cmd.AddCommand(diffCmd)
 
-	fs.BoolVarP(&cfg.interactive, "interactive", "i", false, "")
-	fs.BoolVarP(&cfg.tui.Fullscreen, "fullscreen", "A", false, "")
-	fs.BoolVarP(&cfg.fitTerm, "fit-terminal-width", "t", false, "")
+	cmd.AddCommand(&cobra.Command{
+		Use:  "watch [PATTERN...]",
+		Args: cobra.ArbitraryArgs,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return executeWatch(&cfg, args)
+		},
+	})
 
+	fs := cmd.PersistentFlags()
+	fs.BoolVarP(&cfg.tree.FullMatch, "full-match", "f", false, "")
+	fs.BoolVarP(&cfg.tree.Regex, "regex", "E", false, "")
+	fs.BoolVarP(&cfg.tree.IgnoreCase, "ignore-case", "I", false, "")
+	fs.BoolVarP(&cfg.tree.Query, "query", "q", false, "")
+	fs.IntSliceVar(&cfg.tree.Select.PIDs, "pid", nil, "")
+	fs.IntSliceVar(&cfg.tree.Select.PPIDs, "ppid", nil, "")
+	fs.IntSliceVar(&cfg.tree.Select.SIDs, "sid", nil, "")
+	fs.IntSliceVar(&cfg.tree.Select.PGIDs, "pgid", nil, "")
+	fs.StringSliceVar(&cfg.tree.Select.Users, "user", nil, "")
+	fs.StringSliceVar(&cfg.tree.Select.Cgroups, "in-cgroup", nil, "")
+	fs.Var(&cfg.tree.Select.Namespaces, "in-ns", "")
+	fs.StringSliceVar(&cfg.tree.Select.HasCaps, "has-cap", nil, "")
+	fs.BoolVar(&cfg.tree.Select.SelfSubtree, "self-subtree", false, "")
+	fs.Var(&cfg.tree.Ancestors, "ancestors", "")
+	fs.Var(&cfg.tree.Descendants, "descendants", "")
+	fs.BoolVar(&cfg.tree.Siblings, "siblings", false, "")
+
+	// watch prints events instead of a tree, so it doesn't get these
+	ofs := pflag.NewFlagSet("output", pflag.ContinueOnError)
+	ofs.BoolVarP(&cfg.tree.PCfg.Workdir, "workdir", "w", false, "")
+	ofs.BoolVarP(&cfg.tree.PCfg.UGID, "uid-gid", "u", false, "")
+	ofs.BoolVarP(&cfg.tree.NumericIDs, "numeric-ids", "n", false, "")
+	ofs.BoolVarP(&cfg.tree.PCfg.NamespacePID, "namespace-pid", "N", false, "")
+	ofs.BoolVarP(&cfg.tree.PCfg.Threads, "threads", "T", false, "")
+	ofs.BoolVarP(&cfg.tree.PCfg.FDs, "file-descriptors", "F", false, "")
+	ofs.BoolVarP(&cfg.tree.PCfg.Stats, "stats", "s", false, "")
+	ofs.BoolVar(&cfg.tree.PCfg.Cgroup, "cgroup", false, "")
+	ofs.BoolVarP(&cfg.tree.PCfg.Security, "security", "S", false, "")
+	ofs.StringSliceVar(&cfg.tree.NSBoundaries, "ns-boundaries", nil, "")
+	ofs.IntVar(&cfg.tree.PIDNamespaceOf, "pid-ns-of", 0, "")
+	ofs.DurationVar(&cfg.tree.CPUInterval, "cpu-interval", 500*time.Millisecond, "")
+	ofs.BoolVarP(&cfg.tree.ShowDead, "show-dead", "D", false, "")
+
+	ofs.BoolVar(&cfg.tree.GroupByCgroup, "group-by-cgroup", false, "")
+	ofs.Var(&cfg.tree.Sort, "sort", "")
+	ofs.BoolVar(&cfg.tree.Reverse, "reverse", false, "")
+
+	ofs.StringVarP(&cfg.output, "output", "o", "text", "")
+	ofs.StringVar(&cfg.format, "format", "", "")
+	ofs.BoolVar(&cfg.tree.NoIndent, "no-indent", false, "")
+
+	ofs.BoolVarP(&cfg.interactive, "interactive", "i", false, "")
+	ofs.BoolVarP(&cfg.tui.Fullscreen, "fullscreen", "A", false, "")
+	ofs.DurationVar(&cfg.tui.Refresh, "refresh", 2*time.Second, "")
+	ofs.BoolVarP(&cfg.fitTerm, "fit-terminal-width", "t", false, "")
+
+	cmd.Flags().AddFlagSet(ofs)
+	diffCmd.Flags().AddFlagSet(ofs)
+
+	// these only make sense for a single tree, so keep them off the subcommands
+	rfs := cmd.Flags()
+	rfs.StringVar(&cfg.tree.Snapshot, "from-snapshot", "", "")
 	// TODO: use different variable maybe
-	fs.BoolVar(&cfg.inspectAllFDs, "inspect-all-fds", false, "")
-	fs.StringVar(&cfg.dumpProcSnapshot, "dump-process-snapshot", "", "")
+	rfs.BoolVar(&cfg.inspectAllFDs, "inspect-all-fds", false, "")
+	rfs.StringVar(&cfg.dumpProcSnapshot, "dump-process-snapshot", "", "")
 
 	fs.BoolVar(&cfg.showBenchmarks, "benchmarks", false, "")
 
@@ -48,6 +113,8 @@ type config struct {
 	tui              tui.Config
 	fitTerm          bool
 	interactive      bool
+	output           string
+	format           string
 	dumpProcSnapshot string
 	inspectAllFDs    bool
 	showBenchmarks   bool
@@ -58,11 +125,8 @@ func execute(cfg *config, args []string) error {
 		defer benchmark.Dump()
 	}
 
-	if cfg.interactive {
-		cfg.tree.FitTermHeight = true
-		cfg.tree.FitTermWidth = true
-	} else if cfg.fitTerm {
-		cfg.tree.FitTermWidth = true
+	if err := cfg.prepare(); err != nil {
+		return err
 	}
 	if cfg.inspectAllFDs {
 		cfg.tree.PCfg.FDs = true
@@ -81,27 +145,101 @@ func execute(cfg *config, args []string) error {
 		return inspectAllFDs(pst)
 	}
 
-	if len(args) != 1 {
-		return fmt.Errorf("invalid number of positional arguments: %d (must be 1)", len(args))
+	return show(cfg, pst, args)
+}
+
+func executeDiff(cfg *config, args []string) error {
+	if cfg.showBenchmarks {
+		defer benchmark.Dump()
+	}
+
+	if err := cfg.prepare(); err != nil {
+		return err
+	}
+
+	pst, err := tree.Diff(&cfg.tree, args[0], args[1])
+	if err != nil {
+		return err
 	}
 
-	pst.Filter(args[0])
+	return show(cfg, pst, args[2:])
+}
+
+func (cfg *config) prepare() error {
+	if cfg.format != "" {
+		need, err := tree.FormatNeeds(cfg.format)
+		if err != nil {
+			return err
+		}
+
+		pcfg := &cfg.tree.PCfg
+		pcfg.Workdir = pcfg.Workdir || need.Workdir
+		pcfg.UGID = pcfg.UGID || need.UGID
+		pcfg.NamespacePID = pcfg.NamespacePID || need.NamespacePID
+		pcfg.Stats = pcfg.Stats || need.Stats
+		pcfg.Cgroup = pcfg.Cgroup || need.Cgroup
+		pcfg.Namespaces = pcfg.Namespaces || need.Namespaces
+	}
+
+	if cfg.interactive {
+		cfg.tree.FitTermHeight = true
+		cfg.tree.FitTermWidth = true
+	} else if cfg.fitTerm {
+		cfg.tree.FitTermWidth = true
+	}
+
+	return nil
+}
+
+func show(cfg *config, pst *tree.Tree, patterns []string) (err error) {
+	if err = pst.Filter(patterns...); err != nil {
+		return err
+	}
 
 	if cfg.interactive {
-		err = tui.Run(&cfg.tui, pst)
-	} else {
-		_, err = fmt.Println(pst.View())
+		return tui.Run(&cfg.tui, pst)
+	}
+
+	switch cfg.output {
+	case "text":
+		if cfg.format != "" {
+			err = pst.WriteFormat(os.Stdout, cfg.format)
+		} else {
+			_, err = fmt.Println(pst.View())
+		}
+	case "json":
+		err = pst.WriteJSON(os.Stdout)
+	case "dot":
+		err = pst.WriteDOT(os.Stdout)
+	case "mermaid":
+		err = pst.WriteMermaid(os.Stdout)
+	case "html":
+		err = pst.WriteHTML(os.Stdout)
+	default:
+		err = fmt.Errorf("unsupported output format %q", cfg.output)
 	}
 
 	return err
 }
 
-func dumpProcSnapshot(path string, pst *tree.Tree) error {
-	// TODO: implement
-	return nil
+func dumpProcSnapshot(path string, pst *tree.Tree) (err error) {
+	if path == "-" {
+		return pst.WriteSnapshot(os.Stdout)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating snapshot file: %w", err)
+	}
+	defer func() {
+		if ce := f.Close(); ce != nil {
+			err = errors.Join(err, fmt.Errorf("closing snapshot file: %w", ce))
+		}
+	}()
+
+	return pst.WriteSnapshot(f)
 }
 
 func inspectAllFDs(pst *tree.Tree) error {
-	// TODO: implement
-	return nil
+	return pst.WriteFDReport(os.Stdout)
 }
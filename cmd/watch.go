@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/kevwargo/go-pst/internal/benchmark"
+	"github.com/kevwargo/go-pst/internal/procwatch"
+	"github.com/kevwargo/go-pst/internal/pst/tree"
+)
+
+func executeWatch(cfg *config, args []string) error {
+	if cfg.showBenchmarks {
+		defer benchmark.Dump()
+	}
+
+	cfg.tree.Headless = true
+
+	pst, err := tree.Build(&cfg.tree)
+	if err != nil {
+		return err
+	}
+
+	if !pst.Live() {
+		return errors.New("watch requires a live process tree")
+	}
+
+	if err = pst.Filter(args...); err != nil {
+		return err
+	}
+
+	watcher, err := procwatch.Watch()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		watcher.Close()
+	}()
+
+	for {
+		ev, err := watcher.Recv()
+		if err != nil {
+			return fmt.Errorf("procwatcher error: %w", err)
+		}
+		if ev == nil {
+			return nil
+		}
+
+		if err = pst.WatchEvent(os.Stdout, ev); err != nil {
+			return err
+		}
+	}
+}
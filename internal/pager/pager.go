@@ -6,7 +6,8 @@ import (
 )
 
 type Pager struct {
-	lines []line
+	lines  []line
+	header *line
 
 	maxWidth  int
 	maxHeight int
@@ -26,6 +27,16 @@ func (p *Pager) WriteLine(fixed, scrollable string) {
 	p.needsRefresh = true
 }
 
+// SetHeader sets a line kept above the others when scrolling vertically
+func (p *Pager) SetHeader(fixed, scrollable string) {
+	p.header = &line{
+		fixed:      fixed,
+		scrollable: scrollable,
+	}
+
+	p.needsRefresh = true
+}
+
 func (p *Pager) SetMaxWidth(w int) {
 	p.maxWidth = w
 	p.needsRefresh = true
@@ -49,13 +60,13 @@ func (p *Pager) Down() {
 }
 
 func (p *Pager) PageUp() {
-	if p.incYPos(1 - p.maxHeight) {
+	if p.incYPos(1 - p.bodyHeight()) {
 		p.needsRefresh = true
 	}
 }
 
 func (p *Pager) PageDown() {
-	if p.incYPos(p.maxHeight - 1) {
+	if p.incYPos(p.bodyHeight() - 1) {
 		p.needsRefresh = true
 	}
 }
@@ -74,6 +85,7 @@ func (p *Pager) Right() {
 
 func (p *Pager) Reset() {
 	p.lines = p.lines[:0]
+	p.header = nil
 }
 
 func (p *Pager) View() string {
@@ -89,13 +101,14 @@ func (p *Pager) View() string {
 }
 
 func (p *Pager) incYPos(delta int) bool {
-	if p.maxHeight <= 0 || len(p.lines) <= p.maxHeight {
+	height := p.bodyHeight()
+	if height <= 0 || len(p.lines) <= height {
 		return false
 	}
 
 	old := p.yPos
 	p.yPos = max(p.yPos+delta, 0)
-	p.yPos = min(p.yPos, len(p.lines)-p.maxHeight)
+	p.yPos = min(p.yPos, len(p.lines)-height)
 
 	return old != p.yPos
 }
@@ -121,6 +134,10 @@ func (p *Pager) refresh() {
 	p.buf.Reset()
 
 	lines := p.visibleLines()
+	if p.header != nil {
+		lines = append([]line{*p.header}, lines...)
+	}
+
 	for i, line := range lines {
 		textLine := line.clamp(p.xPos, p.maxWidth)
 		if i == len(lines)-1 {
@@ -134,9 +151,17 @@ func (p *Pager) refresh() {
 }
 
 func (p *Pager) visibleLines() []line {
-	if p.maxHeight > 0 && len(p.lines) > p.maxHeight {
-		return p.lines[p.yPos : p.yPos+p.maxHeight]
+	if height := p.bodyHeight(); height > 0 && len(p.lines) > height {
+		return p.lines[p.yPos : p.yPos+height]
 	}
 
 	return p.lines
 }
+
+func (p *Pager) bodyHeight() int {
+	if p.header != nil && p.maxHeight > 1 {
+		return p.maxHeight - 1
+	}
+
+	return p.maxHeight
+}
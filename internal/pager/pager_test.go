@@ -0,0 +1,33 @@
+package pager
+
+import "testing"
+
+func TestHeader(t *testing.T) {
+	var p Pager
+	p.SetMaxHeight(3)
+	p.SetHeader("HEADER", "")
+	for _, s := range []string{"a", "b", "c", "d"} {
+		p.WriteLine(s, "")
+	}
+
+	if got, want := p.View(), "HEADER\na\nb"; got != want {
+		t.Errorf("View() = %q, want %q", got, want)
+	}
+
+	p.PageDown()
+	if got, want := p.View(), "HEADER\nb\nc"; got != want {
+		t.Errorf("View() after PageDown = %q, want %q", got, want)
+	}
+
+	p.Down()
+	p.Down()
+	if got, want := p.View(), "HEADER\nc\nd"; got != want {
+		t.Errorf("View() at the bottom = %q, want %q", got, want)
+	}
+
+	p.Reset()
+	p.WriteLine("x", "")
+	if got, want := p.View(), "x"; got != want {
+		t.Errorf("View() after Reset = %q, want %q", got, want)
+	}
+}
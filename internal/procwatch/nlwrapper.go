@@ -153,6 +153,43 @@ func (w *watcher) deliverMessage(nlmsgDataPtr unsafe.Pointer) {
 			PID: int(data.process_tgid),
 			TID: int(data.process_pid),
 		}}
+	case procEventUid:
+		data := (*C.struct_id_proc_event)(dataPtr)
+		w.msgCh <- watcherMessage{ev: EventUID{
+			PID:          int(data.process_tgid),
+			TID:          int(data.process_pid),
+			RealUID:      int(unionU32(unsafe.Pointer(&data.r))),
+			EffectiveUID: int(unionU32(unsafe.Pointer(&data.e))),
+		}}
+	case procEventGid:
+		data := (*C.struct_id_proc_event)(dataPtr)
+		w.msgCh <- watcherMessage{ev: EventGID{
+			PID:          int(data.process_tgid),
+			TID:          int(data.process_pid),
+			RealGID:      int(unionU32(unsafe.Pointer(&data.r))),
+			EffectiveGID: int(unionU32(unsafe.Pointer(&data.e))),
+		}}
+	case procEventSid:
+		data := (*C.struct_sid_proc_event)(dataPtr)
+		w.msgCh <- watcherMessage{ev: EventSID{
+			PID: int(data.process_tgid),
+			TID: int(data.process_pid),
+		}}
+	case procEventPtrace:
+		data := (*C.struct_ptrace_proc_event)(dataPtr)
+		w.msgCh <- watcherMessage{ev: EventPtrace{
+			PID:       int(data.process_tgid),
+			TID:       int(data.process_pid),
+			TracerPID: int(data.tracer_tgid),
+			TracerTID: int(data.tracer_pid),
+		}}
+	case procEventCoredump:
+		data := (*C.struct_coredump_proc_event)(dataPtr)
+		w.msgCh <- watcherMessage{ev: EventCoredump{
+			PID:       int(data.process_tgid),
+			TID:       int(data.process_pid),
+			ParentPID: int(data.parent_tgid),
+		}}
 	case procEventComm:
 		data := (*C.struct_comm_proc_event)(dataPtr)
 		w.msgCh <- watcherMessage{ev: EventComm{
@@ -168,20 +205,24 @@ func (w *watcher) deliverMessage(nlmsgDataPtr unsafe.Pointer) {
 				TID: int(data.process_pid),
 			}}
 		} else {
-			w.msgCh <- watcherMessage{ev: EventExitProc{
-				PID:       int(data.process_tgid),
-				ParentPID: int(data.parent_tgid),
+			ev := EventExitProc{
+				PID:          int(data.process_tgid),
+				ParentPID:    int(data.parent_tgid),
+				ParentSignal: int(data.exit_signal),
+			}
 
-				// TODO: properly parse exit_code, using WIFEXITED, etc.
-				// For now it just duplicates the logic from
-				// /usr/include/x86_64-linux-gnu/bits/waitstatus.h
-				ExitCode:   int((data.exit_code & 0xff00) >> 8),
-				ExitSignal: int(data.exit_code & 0x7f),
-			}}
+			ev.setWaitStatus(uint32(data.exit_code))
+
+			w.msgCh <- watcherMessage{ev: ev}
 		}
 	}
 }
 
+// cgo represents C unions as byte arrays, so read the u32 members directly
+func unionU32(ptr unsafe.Pointer) uint32 {
+	return *(*uint32)(ptr)
+}
+
 type nlmsgType uint16
 
 const (
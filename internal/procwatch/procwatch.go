@@ -28,10 +28,27 @@ type EventComm struct {
 }
 
 type EventExitProc struct {
-	PID        int
-	ParentPID  int
-	ExitCode   int
-	ExitSignal int
+	PID          int
+	ParentPID    int
+	ExitCode     int
+	ExitSignal   int
+	CoreDumped   bool
+	ParentSignal int // signal sent to the parent, usually SIGCHLD
+}
+
+// setWaitStatus decodes the wait status the parent will reap, which the kernel
+// reports as the exit code
+func (ev *EventExitProc) setWaitStatus(ws uint32) {
+	status := unix.WaitStatus(ws)
+	switch {
+	case status.Exited():
+		ev.ExitCode = status.ExitStatus()
+	case status.Signaled():
+		ev.ExitSignal = int(status.Signal())
+		ev.CoreDumped = status.CoreDump()
+	case status.Stopped():
+		ev.ExitSignal = int(status.StopSignal())
+	}
 }
 
 type EventExitThread struct {
@@ -39,6 +56,40 @@ type EventExitThread struct {
 	TID int
 }
 
+type EventUID struct {
+	PID          int
+	TID          int
+	RealUID      int
+	EffectiveUID int
+}
+
+type EventGID struct {
+	PID          int
+	TID          int
+	RealGID      int
+	EffectiveGID int
+}
+
+type EventSID struct {
+	PID int
+	TID int
+}
+
+// EventPtrace is sent on both attaching and detaching; in the latter case
+// TracerPID and TracerTID are 0
+type EventPtrace struct {
+	PID       int
+	TID       int
+	TracerPID int
+	TracerTID int
+}
+
+type EventCoredump struct {
+	PID       int
+	TID       int
+	ParentPID int
+}
+
 type Watcher interface {
 	Recv() (any, error)
 	Close()
@@ -66,6 +117,10 @@ func Watch() (Watcher, error) {
 	return w, nil
 }
 
+func Idle() Watcher {
+	return &idleWatcher{doneCh: make(chan struct{})}
+}
+
 type watcher struct {
 	sock      int
 	msgCh     chan watcherMessage
@@ -93,3 +148,19 @@ func (w *watcher) Close() {
 		unix.Close(w.sock)
 	})
 }
+
+type idleWatcher struct {
+	doneCh    chan struct{}
+	closeOnce sync.Once
+}
+
+func (w *idleWatcher) Recv() (any, error) {
+	<-w.doneCh
+	return nil, nil
+}
+
+func (w *idleWatcher) Close() {
+	w.closeOnce.Do(func() {
+		close(w.doneCh)
+	})
+}
@@ -0,0 +1,29 @@
+package procwatch
+
+import (
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestSetWaitStatus(t *testing.T) {
+	tests := []struct {
+		status uint32
+		want   EventExitProc
+	}{
+		{0, EventExitProc{}},
+		{3 << 8, EventExitProc{ExitCode: 3}},
+		{uint32(unix.SIGKILL), EventExitProc{ExitSignal: int(unix.SIGKILL)}},
+		{uint32(unix.SIGSEGV) | 0x80, EventExitProc{ExitSignal: int(unix.SIGSEGV), CoreDumped: true}},
+		{uint32(unix.SIGSTOP)<<8 | 0x7f, EventExitProc{ExitSignal: int(unix.SIGSTOP)}},
+	}
+
+	for _, tt := range tests {
+		var ev EventExitProc
+		ev.setWaitStatus(tt.status)
+
+		if ev != tt.want {
+			t.Errorf("setWaitStatus(%#x) = %+v, want %+v", tt.status, ev, tt.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package tree
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kevwargo/go-pst/internal/pager"
+)
+
+func (t *Tree) renderCgroupGroups(pg *pager.Pager) {
+	var units []string
+	roots := make(map[string][]*process)
+
+	var walk func(ps []*process, parent *process)
+	walk = func(ps []*process, parent *process) {
+		for _, p := range t.visibleChildren(ps) {
+			unit := cgroupUnit(p.attrs.cgroup)
+			if parent == nil || unit != cgroupUnit(parent.attrs.cgroup) {
+				if _, ok := roots[unit]; !ok {
+					units = append(units, unit)
+				}
+				roots[unit] = append(roots[unit], p)
+			}
+
+			walk(p.children, p)
+		}
+	}
+	walk(t.top, nil)
+
+	slices.Sort(units)
+
+	for _, unit := range units {
+		pg.WriteLine("", fmt.Sprintf("── %s ──", unit))
+		for _, p := range roots[unit] {
+			t.renderProcess(p, pg, 0)
+		}
+	}
+}
+
+// cgroupUnit returns the systemd unit or container scope owning the cgroup
+// path: the deepest service or scope, then the deepest slice, then the path
+// itself (e.g. for cgroupfs-managed containers)
+func cgroupUnit(path string) string {
+	parts := strings.Split(path, "/")
+
+	for _, suffixes := range [][]string{{".service", ".scope"}, {".slice"}} {
+		for i := len(parts) - 1; i >= 0; i-- {
+			for _, suffix := range suffixes {
+				if strings.HasSuffix(parts[i], suffix) {
+					return parts[i]
+				}
+			}
+		}
+	}
+
+	return path
+}
+
+func inCgroup(path, parent string) bool {
+	parent = strings.TrimSuffix(parent, "/")
+
+	return path == parent || parent == "" || strings.HasPrefix(path, parent+"/")
+}
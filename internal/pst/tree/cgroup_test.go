@@ -0,0 +1,45 @@
+package tree
+
+import "testing"
+
+func TestCgroupUnit(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/system.slice/sshd.service", "sshd.service"},
+		{"/user.slice/user-1000.slice/user@1000.service/app.slice/app-firefox.scope", "app-firefox.scope"},
+		{"/user.slice/user-1000.slice/session-2.scope", "session-2.scope"},
+		{"/system.slice/docker-0123abcd.scope/init", "docker-0123abcd.scope"},
+		{"/user.slice/user-1000.slice", "user-1000.slice"},
+		{"/docker/0123abcd", "/docker/0123abcd"},
+		{"/", "/"},
+	}
+
+	for _, tt := range tests {
+		if got := cgroupUnit(tt.path); got != tt.want {
+			t.Errorf("cgroupUnit(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestInCgroup(t *testing.T) {
+	tests := []struct {
+		path   string
+		parent string
+		want   bool
+	}{
+		{"/system.slice/sshd.service", "/system.slice", true},
+		{"/system.slice/sshd.service", "/system.slice/", true},
+		{"/system.slice/sshd.service", "/system.slice/sshd.service", true},
+		{"/system.slice/sshd.service", "/", true},
+		{"/system.slice-other/x.service", "/system.slice", false},
+		{"/user.slice", "/system.slice", false},
+	}
+
+	for _, tt := range tests {
+		if got := inCgroup(tt.path, tt.parent); got != tt.want {
+			t.Errorf("inCgroup(%q, %q) = %v, want %v", tt.path, tt.parent, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package tree
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+type procDiff struct {
+	kind      diffKind
+	oldParent int
+}
+
+type diffKind int
+
+const (
+	diffAdded diffKind = 1 << iota
+	diffRemoved
+	diffExec
+	diffReparent
+	diffUID
+	diffGID
+	diffWorkdir
+	diffFDs
+)
+
+func Diff(cfg *Config, oldPath, newPath string) (*Tree, error) {
+	newCfg := *cfg
+	newCfg.Snapshot = newPath
+
+	t, err := Build(&newCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(oldPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening snapshot: %w", err)
+	}
+	defer f.Close()
+
+	old, err := ReadSnapshot(f)
+	if err != nil {
+		return nil, err
+	}
+
+	t.applyDiff(old)
+
+	return t, nil
+}
+
+func (t *Tree) applyDiff(old *Snapshot) {
+	t.diffMode = true
+
+	seen := make(map[int]bool)
+	removed := make(map[int]*process)
+
+	for i := range old.Processes {
+		op := old.Processes[i].process()
+
+		np := t.pMap[op.id]
+		if np == nil || isPIDReused(op, np) {
+			op.diff = &procDiff{kind: diffRemoved}
+			removed[op.id] = op
+			continue
+		}
+
+		seen[np.id] = true
+		if d := diffProcesses(op, np); d != nil {
+			np.diff = d
+		}
+	}
+
+	for _, p := range t.pMap {
+		if !seen[p.id] {
+			p.diff = &procDiff{kind: diffAdded}
+		}
+	}
+
+	for _, p := range removed {
+		if parent := removed[p.parentID]; parent != nil {
+			parent.children = append(parent.children, p)
+		} else if parent := t.pMap[p.parentID]; parent != nil && seen[parent.id] {
+			parent.children = append(parent.children, p)
+		} else {
+			t.top = append(t.top, p)
+		}
+	}
+}
+
+func isPIDReused(op, np *process) bool {
+	return op.attrs.startTime != 0 && np.attrs.startTime != 0 && op.attrs.startTime != np.attrs.startTime
+}
+
+func diffProcesses(op, np *process) *procDiff {
+	var d procDiff
+
+	if !slices.Equal(op.attrs.args, np.attrs.args) || op.attrs.name != np.attrs.name {
+		d.kind |= diffExec
+	}
+
+	if op.parentID != np.parentID {
+		d.kind |= diffReparent
+		d.oldParent = op.parentID
+	}
+
+	if op.attrs.uid != nil && np.attrs.uid != nil && op.attrs.uid != np.attrs.uid {
+		d.kind |= diffUID
+	}
+
+	if op.attrs.gid != nil && np.attrs.gid != nil && op.attrs.gid != np.attrs.gid {
+		d.kind |= diffGID
+	}
+
+	if op.attrs.workdir != "" && np.attrs.workdir != "" && op.attrs.workdir != np.attrs.workdir {
+		d.kind |= diffWorkdir
+	}
+
+	// endpoints and peers are resolved on load and change with the connection
+	// state, so only the descriptors themselves are compared
+	if op.fds != nil && np.fds != nil && !slices.EqualFunc(op.fds, np.fds, sameFD) {
+		d.kind |= diffFDs
+	}
+
+	if d.kind == 0 {
+		return nil
+	}
+
+	return &d
+}
+
+func sameFD(a, b fileDes) bool {
+	return a.num == b.num && a.link == b.link
+}
+
+func (d *procDiff) marker() string {
+	switch {
+	case d == nil:
+		return "  "
+	case d.kind&diffAdded != 0:
+		return "+ "
+	case d.kind&diffRemoved != 0:
+		return "- "
+	default:
+		return "~ "
+	}
+}
+
+func (d *procDiff) details() string {
+	if d == nil || d.kind&(diffAdded|diffRemoved) != 0 {
+		return ""
+	}
+
+	var changes []string
+	if d.kind&diffExec != 0 {
+		changes = append(changes, "exec")
+	}
+	if d.kind&diffReparent != 0 {
+		changes = append(changes, fmt.Sprintf("ppid:%d", d.oldParent))
+	}
+	if d.kind&diffUID != 0 {
+		changes = append(changes, "uid")
+	}
+	if d.kind&diffGID != 0 {
+		changes = append(changes, "gid")
+	}
+	if d.kind&diffWorkdir != 0 {
+		changes = append(changes, "cwd")
+	}
+	if d.kind&diffFDs != 0 {
+		changes = append(changes, "fds")
+	}
+
+	return fmt.Sprintf("*%s*", strings.Join(changes, ","))
+}
@@ -0,0 +1,85 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyDiffPIDReuse(t *testing.T) {
+	cfg := Config{Ancestors: DepthAll, Descendants: DepthAll}
+	tree := newTestTree(&cfg, []string{"1", "0", "init"}, []string{"2", "1", "nginx"})
+	tree.pMap[1].attrs.startTime = 1
+	tree.pMap[2].attrs.startTime = 200
+
+	tree.applyDiff(&Snapshot{Processes: []SnapshotProcess{
+		{PID: 1, Name: "init", Args: []string{"init"}, StartTime: 1},
+		{PID: 2, ParentPID: 1, Name: "apache", Args: []string{"apache"}, StartTime: 100},
+	}})
+
+	if err := tree.Filter("apache"); err != nil {
+		t.Fatal(err)
+	}
+
+	var removed *process
+	for _, c := range tree.pMap[1].children {
+		if c.diff != nil && c.diff.kind&diffRemoved != 0 {
+			removed = c
+		}
+	}
+	if removed == nil || removed.id != 2 {
+		t.Fatalf("removed process not attached to init: %+v", tree.pMap[1].children)
+	}
+
+	if m := tree.filter.matches[removed]; m != matchDirect {
+		t.Errorf("removed apache match = %v, want direct", m)
+	}
+	if m := tree.filter.matches[tree.pMap[2]]; m != matchNone {
+		t.Errorf("new nginx match = %v, want none", m)
+	}
+
+	if err := tree.Filter(); err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := tree.WriteDOT(&sb); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, node := range []string{"p2 [", "p2_removed [", "p1 -> p2;", "p1 -> p2_removed;"} {
+		if !strings.Contains(sb.String(), node) {
+			t.Errorf("no %q in\n%s", node, sb.String())
+		}
+	}
+}
+
+func TestDiffProcessesFDs(t *testing.T) {
+	fds := func(endpoint string, links ...string) []fileDes {
+		var res []fileDes
+		for i, l := range links {
+			res = append(res, fileDes{num: i, link: l, endpoint: endpoint, peer: endpoint})
+		}
+		return res
+	}
+
+	tests := []struct {
+		old, new []fileDes
+		changed  bool
+	}{
+		{fds("tcp ESTABLISHED", "socket:[1]"), fds("tcp CLOSE_WAIT", "socket:[1]"), false},
+		{fds("", "/dev/null", "pipe:[2]"), fds("", "/dev/null", "pipe:[2]"), false},
+		{fds("", "/dev/null", "pipe:[2]"), fds("", "/dev/null", "pipe:[3]"), true},
+		{fds("", "/dev/null"), fds("", "/dev/null", "pipe:[2]"), true},
+		{nil, fds("", "/dev/null"), false},
+	}
+
+	for _, tt := range tests {
+		op := &process{id: 1, fds: tt.old}
+		np := &process{id: 1, fds: tt.new}
+
+		d := diffProcesses(op, np)
+		if changed := d != nil && d.kind&diffFDs != 0; changed != tt.changed {
+			t.Errorf("%+v -> %+v: fds changed %v, want %v", tt.old, tt.new, changed, tt.changed)
+		}
+	}
+}
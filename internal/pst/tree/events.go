@@ -0,0 +1,110 @@
+package tree
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kevwargo/go-pst/internal/procwatch"
+)
+
+func (t *Tree) HandleEvent(event any) {
+	switch ev := event.(type) {
+	case procwatch.EventForkProc:
+		t.HandleNewProcess(ev)
+	case procwatch.EventForkThread:
+		t.HandleNewThread(ev)
+	case procwatch.EventExec:
+		t.HandleExec(ev)
+	case procwatch.EventComm:
+		t.HandleComm(ev)
+	case procwatch.EventExitProc:
+		t.HandleProcessExit(ev)
+	case procwatch.EventExitThread:
+		t.HandleThreadExit(ev)
+	case procwatch.EventUID:
+		t.HandleUID(ev)
+	case procwatch.EventGID:
+		t.HandleGID(ev)
+	case procwatch.EventSID:
+		t.HandleSID(ev)
+	case procwatch.EventPtrace:
+		t.HandlePtrace(ev)
+	case procwatch.EventCoredump:
+		t.HandleCoredump(ev)
+	}
+}
+
+// WatchEvent applies the event to the tree and, if it's a process fork, exec,
+// comm or exit within the matching subtrees, writes a line describing it. The
+// tree should be headless, as nothing else needs rendering
+func (t *Tree) WatchEvent(w io.Writer, event any) error {
+	now := time.Now()
+
+	var (
+		kind string
+		pid  int
+	)
+
+	switch ev := event.(type) {
+	case procwatch.EventForkProc:
+		kind, pid = "fork", ev.PID
+	case procwatch.EventExec:
+		kind, pid = "exec", ev.PID
+	case procwatch.EventComm:
+		if ev.PID == ev.TID {
+			kind, pid = "comm", ev.PID
+		}
+	case procwatch.EventExitProc:
+		kind, pid = "exit", ev.PID
+	}
+
+	// the exited process is removed from pMap when handled, so it has to be
+	// looked up beforehand, while the others are only complete afterwards
+	p := t.pMap[pid]
+	inScope := p != nil && t.inWatchScope(p)
+	t.HandleEvent(event)
+
+	if kind == "" {
+		return nil
+	} else if kind == "exit" {
+		if p != nil {
+			t.removeDead(p)
+		}
+	} else {
+		p = t.pMap[pid]
+		inScope = p != nil && t.inWatchScope(p)
+	}
+
+	if !inScope {
+		return nil
+	}
+
+	// a fork only inherits the parent's cmdline, so just its comm is shown
+	// until the exec
+	desc := p.attrs.cmdline()
+	if kind == "fork" {
+		desc = p.attrs.name
+	}
+
+	line := fmt.Sprintf("%s %-4s %7d %7d %s", now.Format(watchTimeLayout), kind, p.id, p.parentID, desc)
+	if p.exit != nil {
+		line += " " + p.exit.String()
+	}
+
+	_, err := fmt.Fprintln(w, line)
+
+	return err
+}
+
+func (t *Tree) inWatchScope(p *process) bool {
+	if t.filter == nil {
+		return true
+	}
+
+	m := t.filter.matches[p]
+
+	return m == matchDirect || m == matchAsDescendant
+}
+
+const watchTimeLayout = "15:04:05.000"
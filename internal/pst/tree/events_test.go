@@ -0,0 +1,65 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kevwargo/go-pst/internal/procwatch"
+)
+
+func TestHandleUIDKeepsAttrs(t *testing.T) {
+	cfg := Config{}
+	cfg.PCfg.UGID = true
+	tree := newTestTree(&cfg, []string{"1", "0", "init"}, []string{"999999999", "1", "gone", "--flag"})
+
+	tree.HandleUID(procwatch.EventUID{PID: 999999999, TID: 999999999, RealUID: 1000, EffectiveUID: 0})
+	tree.HandleGID(procwatch.EventGID{PID: 999999999, TID: 999999999, RealGID: 100, EffectiveGID: 100})
+
+	p := tree.pMap[999999999]
+	if p.attrs.name != "gone" || len(p.attrs.args) != 2 {
+		t.Errorf("attrs lost on a failed reload: %+v", p.attrs)
+	}
+	if p.attrs.uid == nil || p.attrs.uid.id() != "(r:1000 e:0 ss:0 fs:0)" {
+		t.Errorf("uid not updated from the event: %v", p.attrs.uid)
+	}
+	if p.attrs.gid == nil || p.attrs.gid.id() != "100" {
+		t.Errorf("gid not updated from the event: %v", p.attrs.gid)
+	}
+}
+
+func TestWatchEvent(t *testing.T) {
+	cfg := Config{Headless: true}
+	tree := newTestTree(&cfg, []string{"1", "0", "init"}, []string{"2", "1", "bash", "-l"}, []string{"3", "1", "cron"})
+
+	var out strings.Builder
+	for _, ev := range []any{
+		procwatch.EventForkProc{PID: 4, ParentPID: 2},
+		procwatch.EventExitProc{PID: 4, ExitCode: 1},
+		procwatch.EventForkProc{PID: 5, ParentPID: 2},
+	} {
+		if err := tree.WatchEvent(&out, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got lines %q", lines)
+	}
+	if !strings.HasSuffix(lines[0], " fork       4       2 bash") {
+		t.Errorf("fork line %q doesn't show the comm", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], " exit       4       2 bash -l *e:1*") {
+		t.Errorf("unexpected exit line %q", lines[1])
+	}
+
+	if tree.pager != nil {
+		t.Error("headless tree rendered")
+	}
+	if n := len(tree.pMap[2].children); n != 1 || tree.pMap[2].children[0].id != 5 {
+		t.Errorf("exited process not removed from its parent: %d children", n)
+	}
+	if len(tree.pMap[1].children) != 2 {
+		t.Error("unrelated processes removed on exit")
+	}
+}
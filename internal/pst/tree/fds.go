@@ -0,0 +1,144 @@
+package tree
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type fdHolder struct {
+	proc *process
+	nums []int
+}
+
+func (t *Tree) indexFDs() map[string][]*fdHolder {
+	index := make(map[string][]*fdHolder)
+
+	for _, p := range t.pMap {
+		for _, fd := range p.fds {
+			if strings.HasPrefix(fd.link, "error:") {
+				continue
+			}
+
+			holders := index[fd.link]
+			if n := len(holders); n > 0 && holders[n-1].proc == p {
+				holders[n-1].nums = append(holders[n-1].nums, fd.num)
+				continue
+			}
+
+			index[fd.link] = append(holders, &fdHolder{proc: p, nums: []int{fd.num}})
+		}
+	}
+
+	for _, holders := range index {
+		slices.SortFunc(holders, func(a, b *fdHolder) int { return a.proc.id - b.proc.id })
+	}
+
+	return index
+}
+
+func (t *Tree) WriteFDReport(w io.Writer) error {
+	index := t.indexFDs()
+
+	targets := slices.SortedFunc(maps.Keys(index), func(a, b string) int {
+		if diff := cmp.Compare(fdKind(a), fdKind(b)); diff != 0 {
+			return diff
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	bw := bufio.NewWriter(w)
+
+	for _, target := range targets {
+		holders := index[target]
+
+		if ep := holders[0].proc.fdEndpoint(target); ep != "" {
+			target = fmt.Sprintf("%s %s", target, ep)
+		}
+
+		var shared string
+		if len(holders) > 1 && fdKind(target) <= fdKindSocket {
+			shared = " *shared*"
+		}
+
+		fmt.Fprintf(bw, "%s%s\n", target, shared)
+
+		for _, h := range holders {
+			nums := make([]string, len(h.nums))
+			for i, n := range h.nums {
+				nums[i] = strconv.Itoa(n)
+			}
+
+			fmt.Fprintf(bw, "  [%d] %s fd %s\n", h.proc.id, h.proc.attrs.name, strings.Join(nums, ","))
+		}
+	}
+
+	return bw.Flush()
+}
+
+func fdKind(link string) int {
+	switch {
+	case strings.HasPrefix(link, "pipe:"):
+		return fdKindPipe
+	case strings.HasPrefix(link, "socket:"):
+		return fdKindSocket
+	case strings.HasPrefix(link, "anon_inode:"):
+		return fdKindAnonInode
+	default:
+		return fdKindFile
+	}
+}
+
+const (
+	fdKindPipe = iota
+	fdKindSocket
+	fdKindAnonInode
+	fdKindFile
+)
+
+type fdPeer struct {
+	proc *process
+	num  int
+}
+
+func (t *Tree) fdPeers(p *process, fd fileDes) []fdPeer {
+	link := fd.peer
+	if link == "" {
+		if fdKind(fd.link) != fdKindPipe {
+			return nil
+		}
+
+		link = fd.link
+	}
+
+	var peers []fdPeer
+	for _, h := range t.fdIndex[link] {
+		if h.proc == p {
+			continue
+		}
+
+		for _, pfd := range h.proc.fds {
+			if pfd.link == link && fd.mode.pairsWith(pfd.mode) {
+				peers = append(peers, fdPeer{proc: h.proc, num: pfd.num})
+			}
+		}
+	}
+
+	return peers
+}
+
+func (p *process) fdEndpoint(link string) string {
+	for _, fd := range p.fds {
+		if fd.link == link {
+			return fd.endpoint
+		}
+	}
+
+	return ""
+}
@@ -1,6 +1,8 @@
 package tree
 
 import (
+	"fmt"
+	"regexp"
 	"slices"
 	"strconv"
 	"strings"
@@ -11,7 +13,8 @@ import (
 
 type filter struct {
 	fn      filterFn
-	matches map[int]matchType
+	veto    filterFn
+	matches map[*process]matchType
 }
 
 type matchType int
@@ -21,17 +24,77 @@ const (
 	matchDirect
 	matchAsDescendant
 	matchAsAncestor
+	matchAsSibling
+	matchPassthrough
 )
 
+func (m matchType) String() string {
+	switch m {
+	case matchDirect:
+		return "direct"
+	case matchAsDescendant:
+		return "descendant"
+	case matchAsAncestor:
+		return "ancestor"
+	case matchAsSibling:
+		return "sibling"
+	case matchPassthrough:
+		return "passthrough"
+	default:
+		return "none"
+	}
+}
+
 type filterFn func(*process) bool
 
-func (t *Tree) Filter(pattern string) {
+func (t *Tree) Filter(patterns ...string) error {
+	fn, veto, need, err := t.initFilterFn(patterns)
+	if err != nil {
+		return err
+	}
+
+	selectFn, selectNeed, err := t.initSelectorFn()
+	if err != nil {
+		return err
+	}
+	need.UGID = need.UGID || selectNeed.UGID
+	need.Cgroup = need.Cgroup || selectNeed.Cgroup
+	need.Namespaces = need.Namespaces || selectNeed.Namespaces
+	need.Security = need.Security || selectNeed.Security
+
+	switch {
+	case fn == nil:
+		fn = selectFn
+	case selectFn != nil:
+		fn = andFn(fn, selectFn)
+	}
+
+	if veto != nil {
+		if fn == nil {
+			fn = func(*process) bool { return true }
+		}
+		fn = andFn(fn, notFn(veto))
+	}
+
+	if fn == nil {
+		t.filter = nil
+		t.refreshMatches()
+		return nil
+	}
+
 	t.filter = &filter{
-		fn:      t.initMatchFn(pattern),
-		matches: make(map[int]matchType),
+		fn:      fn,
+		veto:    veto,
+		matches: make(map[*process]matchType),
+	}
+
+	if t.require(need) {
+		return t.reload()
 	}
 
 	t.refreshMatches()
+
+	return nil
 }
 
 func (t *Tree) refreshMatches() {
@@ -39,60 +102,251 @@ func (t *Tree) refreshMatches() {
 
 	// TODO: take dead into account
 
-	clear(t.filter.matches)
-	for _, p := range t.top {
-		t.matchProcess(p)
+	if t.filter != nil {
+		clear(t.filter.matches)
+		t.matchProcesses(t.top)
+		t.matchDescendants(t.top, -1)
+		if t.filter.veto != nil {
+			t.vetoMatches(t.top)
+		}
 	}
 
 	t.refreshView()
 }
 
-func (t *Tree) matchProcess(p *process) {
+// matchProcesses returns the distance from ps to the closest direct match in
+// their subtrees, or -1 if there is none
+func (t *Tree) matchProcesses(ps []*process) int {
+	dist := -1
+	for _, p := range ps {
+		if d := t.matchProcess(p); d >= 0 && (dist < 0 || d < dist) {
+			dist = d
+		}
+	}
+
+	if dist == 0 && t.cfg.Siblings {
+		for _, p := range ps {
+			if m := t.filter.matches[p]; m == matchNone || m == matchPassthrough {
+				t.filter.matches[p] = matchAsSibling
+			}
+		}
+	}
+
+	return dist
+}
+
+func (t *Tree) matchProcess(p *process) int {
 	if p.exit != nil && !t.cfg.ShowDead {
-		return
+		return -1
 	}
 
+	dist := t.matchProcesses(p.children)
+
 	if t.filter.fn(p) {
-		t.filter.matches[p.id] = matchDirect
-		t.matchDescendants(p)
+		t.filter.matches[p] = matchDirect
+		return 0
+	}
+
+	if dist < 0 {
+		return dist
+	}
+
+	dist++
+	if t.cfg.Ancestors.allows(dist) {
+		t.filter.matches[p] = matchAsAncestor
 	} else {
-		for _, c := range p.children {
-			t.matchProcess(c)
+		t.filter.matches[p] = matchPassthrough
+	}
+
+	return dist
+}
+
+func (t *Tree) matchDescendants(ps []*process, depth int) {
+	for _, p := range ps {
+		if p.exit != nil && !t.cfg.ShowDead {
+			continue
+		}
 
-			if t.filter.matches[c.id] != matchNone {
-				t.filter.matches[p.id] = matchAsAncestor
+		d := -1
+		if t.filter.matches[p] == matchDirect {
+			d = 0
+		} else if depth >= 0 {
+			d = depth + 1
+			if t.cfg.Descendants.allows(d) {
+				t.filter.matches[p] = matchAsDescendant
 			}
 		}
+
+		t.matchDescendants(p.children, d)
+	}
+}
+
+// vetoMatches turns the processes excluded by negated patterns into passthrough
+// ones, so they're never shown while their matching descendants still are
+func (t *Tree) vetoMatches(ps []*process) {
+	for _, p := range ps {
+		if m := t.filter.matches[p]; m != matchNone && t.filter.veto(p) {
+			t.filter.matches[p] = matchPassthrough
+		}
+
+		t.vetoMatches(p.children)
 	}
 }
 
-func (t *Tree) matchDescendants(p *process) {
-	for _, c := range p.children {
-		if t.filter.fn(c) {
-			t.filter.matches[c.id] = matchDirect
+// initFilterFn returns the function matching any of the positive patterns, or
+// nil if there are none, and the one matching any of the negated ones
+func (t *Tree) initFilterFn(patterns []string) (fn, veto filterFn, _ ProcConfig, _ error) {
+	if len(patterns) == 0 {
+		return nil, nil, ProcConfig{}, nil
+	}
+
+	if t.cfg.Query {
+		q, err := parseQuery(strings.Join(patterns, " "))
+		if err != nil {
+			return nil, nil, ProcConfig{}, err
+		}
+
+		return q.fn, nil, q.needs, nil
+	}
+
+	var positive, negative []filterFn
+	for _, pattern := range patterns {
+		negate := false
+		if rest, ok := strings.CutPrefix(pattern, "!"); ok && rest != "" {
+			pattern = rest
+			negate = true
+		} else if strings.HasPrefix(pattern, `\!`) {
+			pattern = pattern[1:]
+		}
+
+		match, err := t.initMatchFn(pattern)
+		if err != nil {
+			return nil, nil, ProcConfig{}, err
+		}
+
+		if negate {
+			negative = append(negative, match)
 		} else {
-			t.filter.matches[c.id] = matchAsDescendant
+			positive = append(positive, match)
 		}
+	}
 
-		t.matchDescendants(c)
+	if len(positive) > 0 {
+		fn = func(p *process) bool { return anyFn(positive, p) }
 	}
+	if len(negative) > 0 {
+		veto = func(p *process) bool { return anyFn(negative, p) }
+	}
+
+	return fn, veto, ProcConfig{}, nil
 }
 
-func (t *Tree) initMatchFn(pattern string) filterFn {
+func (t *Tree) initMatchFn(pattern string) (filterFn, error) {
+	match, err := t.initTextMatchFn(pattern)
+	if err != nil {
+		return nil, err
+	}
+
 	if t.cfg.FullMatch {
 		return func(p *process) bool {
-			return strings.Contains(p.attrs.cmdline(), pattern)
-		}
+			return match(p.attrs.cmdline())
+		}, nil
+	}
+
+	if t.cfg.Regex {
+		// regexps may span several arguments, e.g. "^java .*-Dapp=billing"
+		return func(p *process) bool {
+			return match(strings.Join(p.attrs.args, " "))
+		}, nil
 	}
 
 	return func(p *process) bool {
-		if strconv.Itoa(p.id) == pattern {
-			// TODO: standardize this behavior, maybe with a separate flag
-			return true
+		return slices.ContainsFunc(p.attrs.args, match)
+	}, nil
+}
+
+func (t *Tree) initTextMatchFn(pattern string) (func(string) bool, error) {
+	if t.cfg.Regex {
+		if t.cfg.IgnoreCase {
+			pattern = "(?i)" + pattern
+		}
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
 		}
 
-		return slices.ContainsFunc(p.attrs.args, func(a string) bool {
-			return strings.Contains(a, pattern)
-		})
+		return re.MatchString, nil
 	}
+
+	if t.cfg.IgnoreCase {
+		pattern = strings.ToLower(pattern)
+
+		return func(s string) bool {
+			return strings.Contains(strings.ToLower(s), pattern)
+		}, nil
+	}
+
+	return func(s string) bool {
+		return strings.Contains(s, pattern)
+	}, nil
+}
+
+func anyFn(fns []filterFn, p *process) bool {
+	return slices.ContainsFunc(fns, func(fn filterFn) bool { return fn(p) })
+}
+
+func andFn(a, b filterFn) filterFn {
+	return func(p *process) bool { return a(p) && b(p) }
+}
+
+func orFn(a, b filterFn) filterFn {
+	return func(p *process) bool { return a(p) || b(p) }
+}
+
+func notFn(fn filterFn) filterFn {
+	return func(p *process) bool { return !fn(p) }
+}
+
+type Depth int
+
+const (
+	DepthAll Depth = -1
+)
+
+func (d *Depth) Set(s string) error {
+	switch s {
+	case "all":
+		*d = DepthAll
+	case "none":
+		*d = 0
+	default:
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid depth %q: must be a non-negative number, none or all", s)
+		}
+
+		*d = Depth(n)
+	}
+
+	return nil
+}
+
+func (d Depth) String() string {
+	switch d {
+	case DepthAll:
+		return "all"
+	case 0:
+		return "none"
+	default:
+		return strconv.Itoa(int(d))
+	}
+}
+
+func (d Depth) Type() string {
+	return "depth"
+}
+
+func (d Depth) allows(n int) bool {
+	return d == DepthAll || n <= int(d)
 }
@@ -0,0 +1,154 @@
+package tree
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestInitMatchFn(t *testing.T) {
+	java := &process{id: 1, attrs: attrs{name: "java", args: []string{"java", "-Xmx1g", "-Dapp=billing", "Main"}}}
+
+	tests := []struct {
+		cfg     Config
+		pattern string
+		want    bool
+	}{
+		{Config{}, "app=bill", true},
+		{Config{}, "java -Xmx1g", false},
+		{Config{FullMatch: true}, "java -Xmx1g", true},
+		{Config{IgnoreCase: true}, "MAIN", true},
+		{Config{Regex: true}, "^java .*-Dapp=billing", true},
+		{Config{Regex: true}, "^-Dapp=billing$", false},
+		{Config{Regex: true}, "^JAVA", false},
+		{Config{Regex: true, IgnoreCase: true}, "^JAVA", true},
+	}
+
+	for _, tt := range tests {
+		tree := Tree{cfg: &tt.cfg}
+
+		fn, err := tree.initMatchFn(tt.pattern)
+		if err != nil {
+			t.Fatalf("initMatchFn(%q): %v", tt.pattern, err)
+		}
+
+		if got := fn(java); got != tt.want {
+			t.Errorf("%+v %q: got %v, want %v", tt.cfg, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+// newTestTree builds a tree from pid, ppid and args... entries
+func newTestTree(cfg *Config, procs ...[]string) *Tree {
+	t := Tree{cfg: cfg, pMap: make(map[int]*process)}
+	t.initComparator()
+
+	var order []*process
+	for _, fields := range procs {
+		pid, _ := strconv.Atoi(fields[0])
+		ppid, _ := strconv.Atoi(fields[1])
+
+		p := &process{id: pid, parentID: ppid, attrs: attrs{name: fields[2], args: fields[2:]}}
+		t.pMap[pid] = p
+		order = append(order, p)
+	}
+
+	for _, p := range order {
+		if parent := t.pMap[p.parentID]; parent != nil {
+			parent.children = append(parent.children, p)
+		} else {
+			t.top = append(t.top, p)
+		}
+	}
+
+	return &t
+}
+
+func TestFilterNegations(t *testing.T) {
+	procs := [][]string{
+		{"1", "0", "init"},
+		{"2", "1", "alpha-server"},
+		{"3", "2", "alpha", "beta"},
+		{"4", "2", "helper"},
+		{"5", "3", "worker"},
+		{"6", "1", "beta-daemon"},
+		{"7", "6", "cat"},
+	}
+
+	tests := []struct {
+		patterns []string
+		siblings bool
+		visible  []int
+	}{
+		{[]string{"alpha", "!beta"}, false, []int{1, 2, 4, 5}},
+		{[]string{"alpha", "!beta"}, true, []int{1, 2, 4, 5}},
+		{[]string{"!beta"}, false, []int{1, 2, 4, 5, 7}},
+		{[]string{"worker", "!beta"}, true, []int{1, 2, 5}},
+		{[]string{"helper", "!beta"}, true, []int{1, 2, 4}},
+		{[]string{"cat", "!beta"}, false, []int{1, 7}},
+	}
+
+	for _, tt := range tests {
+		cfg := Config{Ancestors: DepthAll, Descendants: DepthAll, Siblings: tt.siblings}
+		tree := newTestTree(&cfg, procs...)
+
+		if err := tree.Filter(tt.patterns...); err != nil {
+			t.Fatalf("Filter(%q): %v", tt.patterns, err)
+		}
+
+		var visible []int
+		for pid := 1; pid <= len(procs); pid++ {
+			if tree.isProcVisible(tree.pMap[pid]) {
+				visible = append(visible, pid)
+			}
+		}
+
+		if !slices.Equal(visible, tt.visible) {
+			t.Errorf("Filter(%q) siblings=%v: visible %v, want %v", tt.patterns, tt.siblings, visible, tt.visible)
+		}
+	}
+}
+
+func TestDepthSet(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Depth
+		wantErr bool
+	}{
+		{"all", DepthAll, false},
+		{"none", 0, false},
+		{"0", 0, false},
+		{"3", 3, false},
+		{"-1", 0, true},
+		{"some", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		var d Depth
+		err := d.Set(tt.value)
+
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Set(%q): error %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if err == nil && d != tt.want {
+			t.Errorf("Set(%q) = %v, want %v", tt.value, d, tt.want)
+		}
+		if err == nil && tt.value != "0" && d.String() != tt.value {
+			t.Errorf("Set(%q).String() = %q", tt.value, d.String())
+		}
+	}
+}
+
+func TestDepthAllows(t *testing.T) {
+	if !DepthAll.allows(100) {
+		t.Error("DepthAll doesn't allow 100")
+	}
+	if Depth(0).allows(1) {
+		t.Error("none allows 1")
+	}
+	if !Depth(2).allows(2) || Depth(2).allows(3) {
+		t.Error("depth 2 limits are wrong")
+	}
+}
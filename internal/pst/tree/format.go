@@ -0,0 +1,207 @@
+package tree
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+type formatProcess struct {
+	PID     int
+	PPID    int
+	SID     int
+	PGID    int
+	NSPids  []int
+	NS      map[string]uint64
+	Depth   int
+	UID     int
+	GID     int
+	User    string
+	Group   string
+	Groups  []int
+	Name    string
+	Args    []string
+	Cmdline string
+	Workdir string
+	Cgroup  string
+	State   string
+	CPU     float64
+	RSS     uint64
+	VSZ     uint64
+	Start   time.Time
+	Elapsed time.Duration
+	Exit    string
+	Dead    bool
+	Match   string
+}
+
+// formatFieldNeeds lists the fields relying on data which isn't loaded by
+// default
+var formatFieldNeeds = map[string]func(cfg *ProcConfig){
+	"NSPids":  func(cfg *ProcConfig) { cfg.NamespacePID = true },
+	"NS":      func(cfg *ProcConfig) { cfg.Namespaces = true },
+	"UID":     func(cfg *ProcConfig) { cfg.UGID = true },
+	"GID":     func(cfg *ProcConfig) { cfg.UGID = true },
+	"User":    func(cfg *ProcConfig) { cfg.UGID = true },
+	"Group":   func(cfg *ProcConfig) { cfg.UGID = true },
+	"Groups":  func(cfg *ProcConfig) { cfg.UGID = true },
+	"Workdir": func(cfg *ProcConfig) { cfg.Workdir = true },
+	"Cgroup":  func(cfg *ProcConfig) { cfg.Cgroup = true },
+	"CPU":     func(cfg *ProcConfig) { cfg.Stats = true },
+}
+
+// FormatNeeds returns the process data used by the format template
+func FormatNeeds(format string) (ProcConfig, error) {
+	var need ProcConfig
+
+	tmpl, err := template.New("format").Parse(format)
+	if err != nil {
+		return need, fmt.Errorf("parsing format template: %w", err)
+	}
+
+	for _, tt := range tmpl.Templates() {
+		if tt.Tree != nil {
+			walkFormatNode(tt.Tree.Root, &need)
+		}
+	}
+
+	return need, nil
+}
+
+func walkFormatNode(node parse.Node, need *ProcConfig) {
+	var (
+		idents []string
+		nodes  []parse.Node
+	)
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n != nil {
+			for _, c := range n.Nodes {
+				nodes = append(nodes, c)
+			}
+		}
+	case *parse.ActionNode:
+		nodes = append(nodes, n.Pipe)
+	case *parse.PipeNode:
+		if n != nil {
+			for _, c := range n.Cmds {
+				nodes = append(nodes, c)
+			}
+		}
+	case *parse.CommandNode:
+		nodes = append(nodes, n.Args...)
+	case *parse.ChainNode:
+		nodes = append(nodes, n.Node)
+		idents = n.Field
+	case *parse.FieldNode:
+		idents = n.Ident
+	case *parse.VariableNode:
+		idents = n.Ident
+	case *parse.IfNode:
+		nodes = append(nodes, n.Pipe, n.List, n.ElseList)
+	case *parse.RangeNode:
+		nodes = append(nodes, n.Pipe, n.List, n.ElseList)
+	case *parse.WithNode:
+		nodes = append(nodes, n.Pipe, n.List, n.ElseList)
+	case *parse.TemplateNode:
+		nodes = append(nodes, n.Pipe)
+	}
+
+	for _, ident := range idents {
+		if fn, ok := formatFieldNeeds[ident]; ok {
+			fn(need)
+		}
+	}
+
+	for _, c := range nodes {
+		walkFormatNode(c, need)
+	}
+}
+
+func (t *Tree) WriteFormat(w io.Writer, format string) error {
+	tmpl, err := template.New("format").Parse(format)
+	if err != nil {
+		return fmt.Errorf("parsing format template: %w", err)
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := t.writeFormatted(bw, tmpl, t.top, 0); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+func (t *Tree) writeFormatted(w *bufio.Writer, tmpl *template.Template, ps []*process, depth int) error {
+	for _, p := range t.visibleChildren(ps) {
+		if !t.cfg.NoIndent {
+			w.WriteString(strings.Repeat("  ", depth))
+		}
+
+		if err := tmpl.Execute(w, t.formatProcess(p, depth)); err != nil {
+			return fmt.Errorf("formatting process %d: %w", p.id, err)
+		}
+		w.WriteByte('\n')
+
+		if err := t.writeFormatted(w, tmpl, p.children, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *Tree) formatProcess(p *process, depth int) *formatProcess {
+	fp := formatProcess{
+		PID:     p.id,
+		PPID:    p.parentID,
+		SID:     p.attrs.sid,
+		PGID:    p.attrs.pgid,
+		Depth:   depth,
+		UID:     -1,
+		GID:     -1,
+		Name:    p.attrs.name,
+		Args:    p.attrs.args,
+		Cmdline: p.attrs.cmdline(),
+		Workdir: p.attrs.workdir,
+		Cgroup:  p.attrs.cgroup,
+		Groups:  p.attrs.groups,
+		NS:      p.attrs.ns,
+		State:   p.attrs.state,
+		RSS:     p.attrs.rss,
+		VSZ:     p.attrs.vsize,
+		Start:   t.startTime(p),
+		Elapsed: t.elapsed(p).Truncate(time.Second),
+		Dead:    p.exit != nil,
+	}
+	fp.CPU = t.stats(p).cpu
+
+	userName, groupName := t.idNamers()
+	if p.attrs.uid != nil {
+		fp.UID = p.attrs.uid.effectiveID()
+		fp.User = userName(fp.UID)
+	}
+	if p.attrs.gid != nil {
+		fp.GID = p.attrs.gid.effectiveID()
+		fp.Group = groupName(fp.GID)
+	}
+	for _, nsPid := range p.attrs.nsPid {
+		if pid, err := strconv.Atoi(nsPid); err == nil {
+			fp.NSPids = append(fp.NSPids, pid)
+		}
+	}
+	if p.exit != nil {
+		fp.Exit = strings.Trim(p.exit.String(), "*")
+	}
+	if t.filter != nil {
+		fp.Match = t.filter.matches[p].String()
+	}
+
+	return &fp
+}
@@ -0,0 +1,46 @@
+package tree
+
+import "testing"
+
+func TestFormatNeeds(t *testing.T) {
+	tests := []struct {
+		format string
+		want   ProcConfig
+	}{
+		{"{{.PID}} {{.Cmdline}}", ProcConfig{}},
+		{"{{.Workdir}}", ProcConfig{Workdir: true}},
+		{"{{ .Workdir }}", ProcConfig{Workdir: true}},
+		{"{{with .Workdir}}{{.}}{{end}}", ProcConfig{Workdir: true}},
+		{"{{$p := .}}{{$p.Cgroup}}", ProcConfig{Cgroup: true}},
+		{"{{if .Dead}}{{else}}{{$.User}}{{end}}", ProcConfig{UGID: true}},
+		{"{{range .Groups}}{{.}}{{end}}", ProcConfig{UGID: true}},
+		{"{{index .NS \"pid\"}} {{.NSPids}}", ProcConfig{Namespaces: true, NamespacePID: true}},
+		{"{{printf \"%.1f\" .CPU}}", ProcConfig{Stats: true}},
+		{`{{define "w"}}{{.Workdir}}{{end}}{{template "w" .}}`, ProcConfig{Workdir: true}},
+	}
+
+	for _, tt := range tests {
+		got, err := FormatNeeds(tt.format)
+		if err != nil {
+			t.Fatalf("FormatNeeds(%q): %v", tt.format, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("FormatNeeds(%q) = %+v, want %+v", tt.format, got, tt.want)
+		}
+	}
+
+	if _, err := FormatNeeds("{{.PID"); err == nil {
+		t.Error("FormatNeeds accepted an unclosed action")
+	}
+}
+
+func TestFormatProcessSnapshotIDs(t *testing.T) {
+	tree := Tree{cfg: &Config{Snapshot: "old.json"}}
+	p := &process{id: 1, attrs: attrs{uid: scalarUGID(0), gid: scalarUGID(0)}}
+
+	fp := tree.formatProcess(p, 0)
+	if fp.User != "0" || fp.Group != "0" {
+		t.Errorf("snapshot ids resolved locally: user %q, group %q", fp.User, fp.Group)
+	}
+}
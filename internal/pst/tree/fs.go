@@ -11,6 +11,9 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
 )
 
 func intDirEntries(path string) iter.Seq2[int, error] {
@@ -93,6 +96,156 @@ func readAttrsMap(pid int) (map[string]string, error) {
 	return attrs, nil
 }
 
+func readStatFields(pid int) ([]string, error) {
+	data, err := os.ReadFile(pidPath(pid, "stat"))
+	if err != nil {
+		return nil, err
+	}
+
+	// comm (the 2nd field) may contain spaces and parentheses, so skip
+	// everything up to the last closing parenthesis
+	commEnd := bytes.LastIndexByte(data, ')')
+	if commEnd < 0 {
+		return nil, fmt.Errorf("invalid stat for Pid %d: %q", pid, data)
+	}
+
+	return strings.Fields(string(data[commEnd+1:])), nil
+}
+
+func readFDFlags(pid, fd int) (int, error) {
+	f, err := os.Open(pidPath(pid, "fdinfo", strconv.Itoa(fd)))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "flags:"); ok {
+			flags, err := strconv.ParseInt(strings.TrimSpace(value), 8, 64)
+			return int(flags), err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	return 0, fmt.Errorf("no flags in fdinfo of fd %d for Pid %d", fd, pid)
+}
+
+func readCgroup(pid int) (string, error) {
+	f, err := os.Open(pidPath(pid, "cgroup"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	// prefer the unified (v2) hierarchy, falling back to the systemd one on
+	// hybrid setups where the unified path is left at the root
+	var unified, systemd string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) < 3 {
+			continue
+		}
+
+		switch {
+		case parts[0] == "0" && parts[1] == "":
+			unified = parts[2]
+		case parts[1] == "name=systemd":
+			systemd = parts[2]
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	if (unified == "" || unified == "/") && systemd != "" {
+		return systemd, nil
+	}
+	if unified == "" {
+		return "/", nil
+	}
+
+	return unified, nil
+}
+
+// readPIDNSChain returns the inodes of the pid namespace of pid and of its
+// ancestors, up to the one of the caller
+func readPIDNSChain(pid int) ([]uint64, error) {
+	fd, err := unix.Open(pidPath(pid, "ns", "pid"), unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var chain []uint64
+	for {
+		var st unix.Stat_t
+		err := unix.Fstat(fd, &st)
+		if err != nil {
+			unix.Close(fd)
+			return nil, err
+		}
+		chain = append(chain, st.Ino)
+
+		// fails with EPERM above the caller's namespace
+		parent, err := unix.IoctlRetInt(fd, nsGetParent)
+		unix.Close(fd)
+		if err != nil {
+			return chain, nil
+		}
+
+		fd = parent
+	}
+}
+
+func readNamespaces(pid int) map[string]uint64 {
+	ns := make(map[string]uint64)
+
+	for _, typ := range nsTypes {
+		link, err := os.Readlink(pidPath(pid, "ns", typ))
+		if err != nil {
+			continue
+		}
+
+		if inode := linkInode(link); inode != 0 {
+			ns[typ] = inode
+		}
+	}
+
+	return ns
+}
+
+func readBootTime() (time.Time, error) {
+	f, err := os.Open(filepath.Join(procRoot, "stat"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "btime "); ok {
+			btime, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid btime %q: %w", value, err)
+			}
+
+			return time.Unix(btime, 0), nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Time{}, errors.New("no btime in /proc/stat")
+}
+
 func pidPath(pid int, parts ...string) string {
 	parts = append([]string{procRoot, strconv.Itoa(pid)}, parts...)
 	return filepath.Join(parts...)
@@ -101,4 +254,33 @@ func pidPath(pid int, parts ...string) string {
 const (
 	procRoot     = "/proc"
 	dirBatchSize = 100
+
+	// NS_GET_PARENT from linux/nsfs.h, _IO(0xb7, 0x2)
+	nsGetParent = 0xb702
+)
+
+// indices of /proc/PID/stat fields following comm
+const (
+	statState = iota
+	statPPid
+	statPGrp
+	statSession
+	statTTY
+	statTPGid
+	statFlags
+	statMinFlt
+	statCMinFlt
+	statMajFlt
+	statCMajFlt
+	statUTime
+	statSTime
+	statCUTime
+	statCSTime
+	statPriority
+	statNice
+	statNumThreads
+	statITRealValue
+	statStartTime
+	statVSize
+	statRSS
 )
@@ -0,0 +1,177 @@
+package tree
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type graphEdge struct {
+	from  *process
+	to    *process
+	label string
+}
+
+func (t *Tree) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "digraph pst {")
+	fmt.Fprintln(bw, `  node [shape=box, fontname="monospace"];`)
+
+	t.walkVisible(t.top, nil, func(p, parent *process) {
+		var attrs, styles []string
+		if t.isDirectMatch(p) {
+			styles = append(styles, "bold", "filled")
+			attrs = append(attrs, `fillcolor="lightyellow"`)
+		}
+		if p.exit != nil {
+			styles = append(styles, "dashed")
+			attrs = append(attrs, `color="gray"`, `fontcolor="gray"`)
+		}
+		if len(styles) > 0 {
+			// graphviz keeps only the last style attribute
+			attrs = append(attrs, "style="+dotQuote(strings.Join(styles, ",")))
+		}
+
+		fmt.Fprintf(bw, "  %s [label=%s%s];\n", graphNodeID(p), dotQuote(t.graphLabel(p)), dotAttrs(attrs))
+
+		if parent != nil {
+			fmt.Fprintf(bw, "  %s -> %s;\n", graphNodeID(parent), graphNodeID(p))
+		}
+	})
+
+	for _, e := range t.fdEdges() {
+		fmt.Fprintf(bw, "  %s -> %s [style=dashed, color=blue, constraint=false, label=%s];\n",
+			graphNodeID(e.from), graphNodeID(e.to), dotQuote(e.label))
+	}
+
+	fmt.Fprintln(bw, "}")
+
+	return bw.Flush()
+}
+
+func (t *Tree) WriteMermaid(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "graph TD")
+
+	var direct, dead []string
+	t.walkVisible(t.top, nil, func(p, parent *process) {
+		fmt.Fprintf(bw, "  %s[%s]\n", graphNodeID(p), mermaidQuote(t.graphLabel(p)))
+
+		if parent != nil {
+			fmt.Fprintf(bw, "  %s --> %s\n", graphNodeID(parent), graphNodeID(p))
+		}
+
+		if t.isDirectMatch(p) {
+			direct = append(direct, graphNodeID(p))
+		}
+		if p.exit != nil {
+			dead = append(dead, graphNodeID(p))
+		}
+	})
+
+	for _, e := range t.fdEdges() {
+		fmt.Fprintf(bw, "  %s -. %s .-> %s\n", graphNodeID(e.from), mermaidQuote(e.label), graphNodeID(e.to))
+	}
+
+	fmt.Fprintln(bw, "  classDef direct fill:#ffd,stroke:#333,stroke-width:2px")
+	fmt.Fprintln(bw, "  classDef dead stroke-dasharray:5 5,color:#888")
+	if len(direct) > 0 {
+		fmt.Fprintf(bw, "  class %s direct\n", strings.Join(direct, ","))
+	}
+	if len(dead) > 0 {
+		fmt.Fprintf(bw, "  class %s dead\n", strings.Join(dead, ","))
+	}
+
+	return bw.Flush()
+}
+
+func (t *Tree) walkVisible(ps []*process, parent *process, fn func(p, parent *process)) {
+	for _, p := range t.visibleChildren(ps) {
+		fn(p, parent)
+		t.walkVisible(p.children, p, fn)
+	}
+}
+
+func (t *Tree) isDirectMatch(p *process) bool {
+	return t.filter != nil && t.filter.matches[p] == matchDirect
+}
+
+func (t *Tree) fdEdges() []graphEdge {
+	if !t.cfg.PCfg.FDs {
+		return nil
+	}
+
+	visible := make(map[*process]bool)
+	t.walkVisible(t.top, nil, func(p, _ *process) { visible[p] = true })
+
+	var edges []graphEdge
+	t.walkVisible(t.top, nil, func(p, _ *process) {
+		for _, fd := range p.fds {
+			for _, peer := range t.fdPeers(p, fd) {
+				if !visible[peer.proc] {
+					continue
+				}
+
+				// emit every pair once: pipes from the writing end, sockets from
+				// the lower PID
+				if fd.mode == fdModeRead || (fd.mode != fdModeWrite && p.id > peer.proc.id) {
+					continue
+				}
+
+				edges = append(edges, graphEdge{
+					from:  p,
+					to:    peer.proc,
+					label: fmt.Sprintf("fd %d -> fd %d", fd.num, peer.num),
+				})
+			}
+		}
+	})
+
+	return edges
+}
+
+// graphNodeID keeps a removed process apart from the new one which reused its
+// PID in a diff
+func graphNodeID(p *process) string {
+	if p.diff != nil && p.diff.kind&diffRemoved != 0 {
+		return fmt.Sprintf("p%d_removed", p.id)
+	}
+
+	return fmt.Sprintf("p%d", p.id)
+}
+
+func (t *Tree) graphLabel(p *process) string {
+	label := fmt.Sprintf("[%d] %s", p.id, p.attrs.cmdline())
+	if runes := []rune(label); len(runes) > graphLabelWidth {
+		label = string(runes[:graphLabelWidth-3]) + "..."
+	}
+
+	if p.exit != nil {
+		label += " " + p.exit.String()
+	}
+
+	return label
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func dotAttrs(attrs []string) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+
+	return ", " + strings.Join(attrs, ", ")
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
+
+const (
+	graphLabelWidth = 60
+)
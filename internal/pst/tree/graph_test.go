@@ -0,0 +1,38 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteDOTStyles(t *testing.T) {
+	cfg := Config{Ancestors: DepthAll, Descendants: DepthAll, ShowDead: true}
+	tree := newTestTree(&cfg, []string{"1", "0", "init"}, []string{"2", "1", "worker"})
+	tree.pMap[2].exit = &exitStatus{signal: 9}
+
+	if err := tree.Filter("worker"); err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := tree.WriteDOT(&sb); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range strings.Split(sb.String(), "\n") {
+		if !strings.Contains(line, "p2 [") {
+			continue
+		}
+
+		if n := strings.Count(line, "style="); n != 1 {
+			t.Errorf("got %d style attributes in %q", n, line)
+		}
+		if !strings.Contains(line, `style="bold,filled,dashed"`) {
+			t.Errorf("missing combined style in %q", line)
+		}
+
+		return
+	}
+
+	t.Errorf("no node for pid 2 in\n%s", sb.String())
+}
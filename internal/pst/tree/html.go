@@ -0,0 +1,108 @@
+package tree
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+//go:embed report.html.tmpl
+var reportTemplateText string
+
+var reportTemplate = template.Must(template.New("report").Parse(reportTemplateText))
+
+type htmlReport struct {
+	Host      string
+	Generated string
+	Processes []*htmlProcess
+}
+
+type htmlProcess struct {
+	PID      int
+	PPID     int
+	Cmdline  string
+	Name     string
+	Args     []string
+	Workdir  string
+	UGID     string
+	Security string
+	NSPid    string
+	Exit     string
+	Match    string
+	Threads  []string
+	FDs      []string
+	Children []*htmlProcess
+}
+
+func (t *Tree) WriteHTML(w io.Writer) error {
+	report := htmlReport{
+		Generated: time.Now().Format(time.RFC3339),
+		Processes: t.htmlProcesses(t.top),
+	}
+	report.Host, _ = os.Hostname()
+
+	return reportTemplate.Execute(w, &report)
+}
+
+func (t *Tree) htmlProcesses(ps []*process) []*htmlProcess {
+	var hps []*htmlProcess
+
+	for _, p := range t.visibleChildren(ps) {
+		hp := htmlProcess{
+			PID:      p.id,
+			PPID:     p.parentID,
+			Cmdline:  p.attrs.cmdline(),
+			Name:     p.attrs.name,
+			Args:     p.attrs.args,
+			Children: t.htmlProcesses(p.children),
+		}
+
+		if t.cfg.PCfg.Workdir {
+			hp.Workdir = p.attrs.workdir
+		}
+		if p.attrs.uid != nil {
+			hp.UGID = t.formatUGID(p)
+		}
+		if p.attrs.security != nil {
+			hp.Security = p.attrs.security.String()
+		}
+		if p.attrs.nsPid != nil {
+			hp.NSPid = strings.Join(p.attrs.nsPid, " ")
+		}
+		if p.exit != nil {
+			hp.Exit = p.exit.String()
+		}
+		if t.filter != nil {
+			hp.Match = t.filter.matches[p].String()
+		}
+
+		if t.cfg.PCfg.Threads {
+			for _, thr := range p.threads {
+				if thr.dead && !t.cfg.ShowDead {
+					continue
+				}
+
+				desc := fmt.Sprintf("{%d} %s", thr.id, thr.name)
+				if thr.dead {
+					desc += " *dead*"
+				}
+
+				hp.Threads = append(hp.Threads, desc)
+			}
+		}
+
+		if t.cfg.PCfg.FDs {
+			for _, fd := range p.fds {
+				hp.FDs = append(hp.FDs, fmt.Sprintf("%d -> %s", fd.num, t.fdDescription(p, fd)))
+			}
+		}
+
+		hps = append(hps, &hp)
+	}
+
+	return hps
+}
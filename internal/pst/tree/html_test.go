@@ -0,0 +1,79 @@
+package tree
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func htmlTestTree(t *testing.T) *Tree {
+	t.Helper()
+
+	cfg := Config{Ancestors: Depth(1), Descendants: DepthAll}
+	tree := newTestTree(&cfg,
+		[]string{"1", "0", "init"},
+		[]string{"2", "1", "sshd"},
+		[]string{"3", "2", "bash"},
+		[]string{"4", "3", "<script>alert(1)</script>", "--x=<img src=x>"},
+		[]string{"5", "1", "cron"},
+		[]string{"6", "5", "script-runner"},
+	)
+
+	if err := tree.Filter("script"); err != nil {
+		t.Fatal(err)
+	}
+
+	return tree
+}
+
+func TestWriteHTMLEscapes(t *testing.T) {
+	var sb strings.Builder
+	if err := htmlTestTree(t).WriteHTML(&sb); err != nil {
+		t.Fatal(err)
+	}
+
+	out := sb.String()
+	for _, raw := range []string{"<script>alert", "<img"} {
+		if strings.Contains(out, raw) {
+			t.Errorf("unescaped %q in the report", raw)
+		}
+	}
+	if !strings.Contains(out, "&lt;script&gt;alert(1)&lt;/script&gt;") {
+		t.Error("escaped args not found in the report")
+	}
+}
+
+func TestHTMLProcessesMatchView(t *testing.T) {
+	tree := htmlTestTree(t)
+
+	var html []string
+	var walk func(hps []*htmlProcess, depth int)
+	walk = func(hps []*htmlProcess, depth int) {
+		for _, hp := range hps {
+			html = append(html, fmt.Sprintf("%d:%d", depth, hp.PID))
+			walk(hp.Children, depth+1)
+		}
+	}
+	walk(tree.htmlProcesses(tree.top), 0)
+
+	// init and sshd are beyond the ancestors depth, so bash and cron are
+	// flattened to the top
+	want := []string{"0:3", "1:4", "0:5", "1:6"}
+	if !slices.Equal(html, want) {
+		t.Errorf("html processes %v, want %v", html, want)
+	}
+
+	lineRe := regexp.MustCompile(`^( *)[\[(](\d+)[\])]`)
+	var text []string
+	for _, line := range strings.Split(tree.View(), "\n") {
+		if m := lineRe.FindStringSubmatch(line); m != nil {
+			text = append(text, fmt.Sprintf("%d:%s", len(m[1])/2, m[2]))
+		}
+	}
+
+	if !slices.Equal(html, text) {
+		t.Errorf("html processes %v differ from the text view %v", html, text)
+	}
+}
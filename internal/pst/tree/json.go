@@ -0,0 +1,81 @@
+package tree
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+type jsonProcess struct {
+	PID      int               `json:"pid"`
+	PPID     int               `json:"ppid"`
+	NSPids   []int             `json:"nsPids,omitempty"`
+	NS       map[string]uint64 `json:"namespaces,omitempty"`
+	Name     string            `json:"name"`
+	Args     []string          `json:"args"`
+	Workdir  string            `json:"workdir,omitempty"`
+	Cgroup   string            `json:"cgroup,omitempty"`
+	UID      *SnapshotUGID     `json:"uid,omitempty"`
+	GID      *SnapshotUGID     `json:"gid,omitempty"`
+	Groups   []int             `json:"groups,omitempty"`
+	Security *SnapshotSecurity `json:"security,omitempty"`
+	Threads  []SnapshotThread  `json:"threads,omitempty"`
+	FDs      []SnapshotFD      `json:"fds,omitempty"`
+	Exit     *SnapshotExit     `json:"exit,omitempty"`
+	Match    string            `json:"match,omitempty"`
+	Children []*jsonProcess    `json:"children,omitempty"`
+}
+
+func (t *Tree) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+
+	return enc.Encode(t.jsonProcesses(t.top))
+}
+
+func (t *Tree) jsonProcesses(ps []*process) []*jsonProcess {
+	visible := t.visibleChildren(ps)
+	jps := make([]*jsonProcess, len(visible))
+
+	for i, p := range visible {
+		sp := p.snapshot()
+
+		jp := jsonProcess{
+			PID:      p.id,
+			PPID:     p.parentID,
+			Name:     sp.Name,
+			Args:     sp.Args,
+			Workdir:  sp.Workdir,
+			Cgroup:   sp.Cgroup,
+			NS:       sp.Namespaces,
+			UID:      sp.UID,
+			GID:      sp.GID,
+			Groups:   sp.Groups,
+			Security: sp.Security,
+			FDs:      sp.FDs,
+			Exit:     sp.Exit,
+			Children: t.jsonProcesses(p.children),
+		}
+
+		for _, nsPid := range p.attrs.nsPid {
+			if pid, err := strconv.Atoi(nsPid); err == nil {
+				jp.NSPids = append(jp.NSPids, pid)
+			}
+		}
+
+		for _, thr := range sp.Threads {
+			if !thr.Dead || t.cfg.ShowDead {
+				jp.Threads = append(jp.Threads, thr)
+			}
+		}
+
+		if t.filter != nil {
+			jp.Match = t.filter.matches[p].String()
+		}
+
+		jps[i] = &jp
+	}
+
+	return jps
+}
@@ -0,0 +1,162 @@
+package tree
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var nsTypes = []string{"pid", "net", "mnt", "user", "uts", "ipc", "cgroup", "time"}
+
+func nsQueryField(typ string) queryField {
+	return queryField{
+		num: func(p *process) (int, bool) {
+			inode, ok := p.attrs.ns[typ]
+			return int(inode), ok
+		},
+		needs: func(cfg *ProcConfig) { cfg.Namespaces = true },
+	}
+}
+
+type NSSelector struct {
+	Type  string
+	Inode uint64
+}
+
+type NSSelectors []NSSelector
+
+func (s *NSSelectors) Set(v string) error {
+	for _, part := range strings.Split(v, ",") {
+		typ, inode, ok := strings.Cut(part, ":")
+		if !ok || !slices.Contains(nsTypes, typ) {
+			return fmt.Errorf("invalid namespace %q: expected TYPE:INODE with TYPE one of %s", part, strings.Join(nsTypes, ", "))
+		}
+
+		n, err := strconv.ParseUint(strings.Trim(inode, "[]"), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid namespace inode %q: %w", inode, err)
+		}
+
+		*s = append(*s, NSSelector{Type: typ, Inode: n})
+	}
+
+	return nil
+}
+
+func (s *NSSelectors) String() string {
+	parts := make([]string, len(*s))
+	for i, sel := range *s {
+		parts[i] = fmt.Sprintf("%s:%d", sel.Type, sel.Inode)
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func (s *NSSelectors) Type() string {
+	return "type:inode"
+}
+
+func (s NSSelectors) match(p *process) bool {
+	for _, sel := range s {
+		if p.attrs.ns[sel.Type] != sel.Inode {
+			return false
+		}
+	}
+
+	return true
+}
+
+func validateNSTypes(types []string) error {
+	for _, typ := range types {
+		if !slices.Contains(nsTypes, typ) {
+			return fmt.Errorf("invalid namespace type %q: must be one of %s", typ, strings.Join(nsTypes, ", "))
+		}
+	}
+
+	return nil
+}
+
+func (t *Tree) renderNSBoundary(p, parent *process) (string, bool) {
+	var changed []string
+	for _, typ := range t.cfg.NSBoundaries {
+		inode, ok := p.attrs.ns[typ]
+		parentInode, parentOK := parent.attrs.ns[typ]
+		if ok && parentOK && inode != parentInode {
+			changed = append(changed, fmt.Sprintf("%sns %d", typ, inode))
+		}
+	}
+
+	if len(changed) == 0 {
+		return "", false
+	}
+
+	return fmt.Sprintf("── %s ──", strings.Join(changed, ", ")), true
+}
+
+func (t *Tree) validatePIDNamespaceOf() error {
+	pid := t.cfg.PIDNamespaceOf
+	if pid == 0 {
+		return nil
+	}
+
+	root := t.pMap[pid]
+	if root == nil {
+		return fmt.Errorf("pid %d not found", pid)
+	}
+
+	if _, ok := root.attrs.ns["pid"]; !ok || len(root.attrs.nsPid) == 0 {
+		return fmt.Errorf("pid namespace of pid %d is unknown", pid)
+	}
+
+	return nil
+}
+
+// nsPIDView returns the pid of p as seen from the pid namespace of the
+// process selected with Config.PIDNamespaceOf, or false if p lies outside it
+func (t *Tree) nsPIDView(p *process) (string, bool) {
+	root := t.pMap[t.cfg.PIDNamespaceOf]
+	if root == nil || len(root.attrs.nsPid) == 0 {
+		return "", false
+	}
+
+	nsInode, ok := root.attrs.ns["pid"]
+	if !ok {
+		return "", false
+	}
+
+	depth := len(root.attrs.nsPid) - 1
+	if len(p.attrs.nsPid) <= depth {
+		return "", false
+	}
+
+	if p.attrs.ns["pid"] == nsInode || slices.Contains(t.pidNSChain(p), nsInode) {
+		return p.attrs.nsPid[depth], true
+	}
+
+	return "", false
+}
+
+// pidNSChain returns the inodes of the pid namespace of p and its ancestors
+func (t *Tree) pidNSChain(p *process) []uint64 {
+	inode, ok := p.attrs.ns["pid"]
+	if !ok || !t.Live() {
+		return nil
+	}
+
+	if chain, ok := t.pidNSChains[inode]; ok {
+		return chain
+	}
+
+	chain, err := readPIDNSChain(p.id)
+	if err != nil {
+		return nil
+	}
+
+	if t.pidNSChains == nil {
+		t.pidNSChains = make(map[uint64][]uint64)
+	}
+	t.pidNSChains[inode] = chain
+
+	return chain
+}
@@ -0,0 +1,69 @@
+package tree
+
+import "testing"
+
+func TestNSQueryFields(t *testing.T) {
+	for _, typ := range nsTypes {
+		if _, ok := queryFields[typ+"ns"]; !ok {
+			t.Errorf("no query field for %s namespaces", typ)
+		}
+	}
+}
+
+func TestNSSelectorsSet(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"pid:4026531836", "pid:4026531836", false},
+		{"net:[4026531840],mnt:4026531841", "net:4026531840,mnt:4026531841", false},
+		{"pid", "", true},
+		{"foo:1", "", true},
+		{"pid:abc", "", true},
+	}
+
+	for _, tt := range tests {
+		var s NSSelectors
+		err := s.Set(tt.value)
+
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Set(%q): error %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if err == nil && s.String() != tt.want {
+			t.Errorf("Set(%q) = %q, want %q", tt.value, s.String(), tt.want)
+		}
+	}
+}
+
+func TestValidatePIDNamespaceOf(t *testing.T) {
+	tests := []struct {
+		pid     int
+		wantErr string
+	}{
+		{0, ""},
+		{2, ""},
+		{1, "pid namespace of pid 1 is unknown"},
+		{42, "pid 42 not found"},
+	}
+
+	for _, tt := range tests {
+		tree := newTestTree(&Config{PIDNamespaceOf: tt.pid}, []string{"1", "0", "init"}, []string{"2", "1", "sh"})
+		tree.pMap[2].attrs.ns = map[string]uint64{"pid": 4026531836}
+		tree.pMap[2].attrs.nsPid = []string{"2"}
+
+		err := tree.validatePIDNamespaceOf()
+		if got := errorString(err); got != tt.wantErr {
+			t.Errorf("pid %d: got error %q, want %q", tt.pid, got, tt.wantErr)
+		}
+	}
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
@@ -9,14 +9,22 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
 )
 
 type ProcConfig struct {
-	Workdir      bool
-	UGID         bool
-	NamespacePID bool
-	Threads      bool
-	FDs          bool
+	Workdir      bool `json:"workdir"`
+	UGID         bool `json:"ugid"`
+	NamespacePID bool `json:"namespacePid"`
+	Threads      bool `json:"threads"`
+	FDs          bool `json:"fds"`
+	Stats        bool `json:"stats"`
+	Cgroup       bool `json:"cgroup"`
+	Namespaces   bool `json:"namespaces"`
+	Security     bool `json:"security"`
 }
 
 type process struct {
@@ -27,20 +35,52 @@ type process struct {
 	fds      []fileDes
 	exit     *exitStatus
 	children []*process
+	diff     *procDiff
+
+	// set from proc connector events
+	newSession bool
+	coreDumped bool
 }
 
 type fileDes struct {
-	num  int
-	link string
+	num      int
+	link     string
+	mode     fdMode
+	endpoint string
+	peer     string
 }
 
+type fdMode string
+
+const (
+	fdModeUnknown   fdMode = ""
+	fdModeRead      fdMode = "r"
+	fdModeWrite     fdMode = "w"
+	fdModeReadWrite fdMode = "rw"
+)
+
 type attrs struct {
-	name    string
-	args    []string
-	workdir string
-	uid     ugid
-	gid     ugid
-	nsPid   []string
+	name       string
+	args       []string
+	workdir    string
+	cgroup     string
+	uid        ugid
+	gid        ugid
+	groups     []int
+	tracer     int
+	security   *security
+	nsPid      []string
+	ns         map[string]uint64
+	startTime  uint64
+	numThreads int
+	sid        int
+	pgid       int
+	state      string
+	cpuTicks   uint64
+	cpuPercent *float64
+	sampled    time.Time
+	vsize      uint64
+	rss        uint64
 }
 
 type thread struct {
@@ -50,8 +90,40 @@ type thread struct {
 }
 
 type exitStatus struct {
-	code   int
-	signal int
+	code       int
+	signal     int
+	coreDumped bool
+}
+
+func (p *process) markers() string {
+	var markers string
+	if p.attrs.tracer > 0 {
+		markers += fmt.Sprintf("*traced:%d*", p.attrs.tracer)
+	}
+	if p.newSession {
+		markers += "*setsid*"
+	}
+	if p.coreDumped && p.exit == nil {
+		markers += "*core*"
+	}
+
+	return markers
+}
+
+func (e *exitStatus) String() string {
+	if e.signal > 0 {
+		name := unix.SignalName(syscall.Signal(e.signal))
+		if name == "" {
+			name = fmt.Sprintf("SIG%d", e.signal)
+		}
+		if e.coreDumped {
+			name += " core"
+		}
+
+		return "*" + name + "*"
+	}
+
+	return fmt.Sprintf("*e:%d*", e.code)
 }
 
 func loadProc(pid int, cfg *ProcConfig) (*process, error) {
@@ -87,6 +159,8 @@ func (p *process) fork(newPID int) *process {
 			name:    p.attrs.name,
 			args:    p.attrs.args,
 			workdir: p.attrs.workdir,
+			cgroup:  p.attrs.cgroup,
+			ns:      p.attrs.ns,
 		},
 	}
 
@@ -119,6 +193,37 @@ func (a *attrs) cmdline() string {
 	return string(data)
 }
 
+// reloadUGIDs refreshes only the ids, so the other attributes survive a
+// failed read
+func (p *process) reloadUGIDs() error {
+	raw, err := readAttrsMap(p.id)
+	if err != nil {
+		return err
+	}
+
+	return p.attrs.parseUGIDs(raw)
+}
+
+func (a *attrs) parseUGIDs(raw map[string]string) error {
+	uid, err := parseUGID(raw["Uid"])
+	if err != nil {
+		return err
+	}
+	gid, err := parseUGID(raw["Gid"])
+	if err != nil {
+		return err
+	}
+
+	groups, err := parseGroups(raw["Groups"])
+	if err != nil {
+		return err
+	}
+
+	a.uid, a.gid, a.groups = uid, gid, groups
+
+	return nil
+}
+
 func (p *process) loadAttrs(cfg *ProcConfig) error {
 	cmdline, err := readCmdline(p.id)
 	if err != nil {
@@ -130,6 +235,11 @@ func (p *process) loadAttrs(cfg *ProcConfig) error {
 		return err
 	}
 
+	stat, err := readStatFields(p.id)
+	if err != nil {
+		return err
+	}
+
 	p.parentID, err = strconv.Atoi(raw["PPid"])
 	if err != nil {
 		return fmt.Errorf("invalid PPid %q for Pid %d: %w", raw["PPid"], p.id, err)
@@ -137,6 +247,20 @@ func (p *process) loadAttrs(cfg *ProcConfig) error {
 
 	p.attrs = attrs{}
 
+	if len(stat) > statStartTime {
+		p.attrs.startTime, err = strconv.ParseUint(stat[statStartTime], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid starttime %q for Pid %d: %w", stat[statStartTime], p.id, err)
+		}
+
+		p.attrs.sid, _ = strconv.Atoi(stat[statSession])
+		p.attrs.pgid, _ = strconv.Atoi(stat[statPGrp])
+	}
+
+	if err := p.attrs.parseStats(stat); err != nil {
+		return fmt.Errorf("invalid stat for Pid %d: %w", p.id, err)
+	}
+
 	p.attrs.args = cmdline
 	if n, ok := raw["Name"]; ok {
 		p.attrs.name = n
@@ -151,21 +275,34 @@ func (p *process) loadAttrs(cfg *ProcConfig) error {
 		}
 	}
 
-	if cfg.UGID {
-		p.attrs.uid, err = parseUGID(raw["Uid"])
+	if cfg.Cgroup {
+		p.attrs.cgroup, err = readCgroup(p.id)
 		if err != nil {
-			return err
+			p.attrs.cgroup = fmt.Sprintf("!%s", err.Error())
 		}
-		p.attrs.gid, err = parseUGID(raw["Gid"])
-		if err != nil {
+	}
+
+	if cfg.UGID {
+		if err := p.attrs.parseUGIDs(raw); err != nil {
 			return err
 		}
 	}
 
+	if cfg.Security {
+		p.attrs.security = parseSecurity(p.id, raw)
+	}
+
+	p.attrs.numThreads, _ = strconv.Atoi(raw["Threads"])
+	p.attrs.tracer, _ = strconv.Atoi(raw["TracerPid"])
+
 	if cfg.NamespacePID {
 		p.attrs.nsPid = strings.Split(raw["NSpid"], "\t")
 	}
 
+	if cfg.Namespaces {
+		p.attrs.ns = readNamespaces(p.id)
+	}
+
 	return nil
 }
 
@@ -230,10 +367,38 @@ func (p *process) loadFDs(cfg *ProcConfig) error {
 			link = fmt.Sprintf("error:[%s]", err.Error())
 		}
 
-		p.fds = append(p.fds, fileDes{num: fd, link: link})
+		var mode fdMode
+		if fdKind(link) == fdKindPipe {
+			if flags, err := readFDFlags(p.id, fd); err == nil {
+				mode = fdModeFromFlags(flags)
+			}
+		}
+
+		p.fds = append(p.fds, fileDes{num: fd, link: link, mode: mode})
 	}
 
 	slices.SortFunc(p.fds, func(a, b fileDes) int { return a.num - b.num })
 
 	return nil
 }
+
+func fdModeFromFlags(flags int) fdMode {
+	switch flags & unix.O_ACCMODE {
+	case unix.O_RDONLY:
+		return fdModeRead
+	case unix.O_WRONLY:
+		return fdModeWrite
+	case unix.O_RDWR:
+		return fdModeReadWrite
+	default:
+		return fdModeUnknown
+	}
+}
+
+func (m fdMode) pairsWith(other fdMode) bool {
+	if m == fdModeRead || m == fdModeWrite {
+		return m != other
+	}
+
+	return true
+}
@@ -0,0 +1,22 @@
+package tree
+
+import "testing"
+
+func TestExitStatusString(t *testing.T) {
+	tests := []struct {
+		exit exitStatus
+		want string
+	}{
+		{exitStatus{}, "*e:0*"},
+		{exitStatus{code: 127}, "*e:127*"},
+		{exitStatus{signal: 9}, "*SIGKILL*"},
+		{exitStatus{signal: 11, coreDumped: true}, "*SIGSEGV core*"},
+		{exitStatus{signal: 99}, "*SIG99*"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.exit.String(); got != tt.want {
+			t.Errorf("%+v: got %q, want %q", tt.exit, got, tt.want)
+		}
+	}
+}
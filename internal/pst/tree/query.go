@@ -0,0 +1,392 @@
+package tree
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type query struct {
+	fn    filterFn
+	needs ProcConfig
+}
+
+type queryParser struct {
+	input string
+	pos   int
+	needs ProcConfig
+}
+
+type queryField struct {
+	text  func(p *process) []string
+	num   func(p *process) (int, bool)
+	needs func(cfg *ProcConfig)
+}
+
+var queryFields = map[string]queryField{
+	"pid": {
+		num: func(p *process) (int, bool) { return p.id, true },
+	},
+	"ppid": {
+		num: func(p *process) (int, bool) { return p.parentID, true },
+	},
+	"sid": {
+		num: func(p *process) (int, bool) { return p.attrs.sid, p.attrs.sid > 0 },
+	},
+	"pgid": {
+		num: func(p *process) (int, bool) { return p.attrs.pgid, p.attrs.pgid > 0 },
+	},
+	"name": {
+		text: func(p *process) []string { return []string{p.attrs.name} },
+	},
+	"args": {
+		text: func(p *process) []string { return p.attrs.args },
+	},
+	"cmdline": {
+		text: func(p *process) []string { return []string{p.attrs.cmdline()} },
+	},
+	"uid": {
+		num: func(p *process) (int, bool) {
+			if p.attrs.uid == nil {
+				return 0, false
+			}
+			return p.attrs.uid.effectiveID(), true
+		},
+		needs: func(cfg *ProcConfig) { cfg.UGID = true },
+	},
+	"gid": {
+		num: func(p *process) (int, bool) {
+			if p.attrs.gid == nil {
+				return 0, false
+			}
+			return p.attrs.gid.effectiveID(), true
+		},
+		needs: func(cfg *ProcConfig) { cfg.UGID = true },
+	},
+	"cwd": {
+		text:  func(p *process) []string { return []string{p.attrs.workdir} },
+		needs: func(cfg *ProcConfig) { cfg.Workdir = true },
+	},
+	"cgroup": {
+		text:  func(p *process) []string { return []string{p.attrs.cgroup} },
+		needs: func(cfg *ProcConfig) { cfg.Cgroup = true },
+	},
+	"cap": {
+		text: func(p *process) []string {
+			if p.attrs.security == nil || p.attrs.security.capEff == nil {
+				return nil
+			}
+			return capSetNames(*p.attrs.security.capEff)
+		},
+		needs: func(cfg *ProcConfig) { cfg.Security = true },
+	},
+	"seccomp": {
+		num: func(p *process) (int, bool) {
+			if p.attrs.security == nil {
+				return 0, false
+			}
+			return p.attrs.security.seccomp, true
+		},
+		needs: func(cfg *ProcConfig) { cfg.Security = true },
+	},
+	"label": {
+		text: func(p *process) []string {
+			if p.attrs.security == nil {
+				return nil
+			}
+			return []string{p.attrs.security.label}
+		},
+		needs: func(cfg *ProcConfig) { cfg.Security = true },
+	},
+	"fd": {
+		text: func(p *process) []string {
+			links := make([]string, len(p.fds))
+			for i, fd := range p.fds {
+				links[i] = strings.TrimSpace(fd.link + " " + fd.endpoint)
+			}
+			return links
+		},
+		needs: func(cfg *ProcConfig) { cfg.FDs = true },
+	},
+	"threads": {
+		num: func(p *process) (int, bool) { return p.attrs.numThreads, p.attrs.numThreads > 0 },
+	},
+	"pidns":    nsQueryField("pid"),
+	"netns":    nsQueryField("net"),
+	"mntns":    nsQueryField("mnt"),
+	"userns":   nsQueryField("user"),
+	"utsns":    nsQueryField("uts"),
+	"ipcns":    nsQueryField("ipc"),
+	"cgroupns": nsQueryField("cgroup"),
+	"timens":   nsQueryField("time"),
+}
+
+func parseQuery(input string) (*query, error) {
+	qp := queryParser{input: input}
+
+	fn, err := qp.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if qp.skipSpace(); qp.pos < len(qp.input) {
+		return nil, qp.errorf("unexpected %q", qp.input[qp.pos:])
+	}
+
+	return &query{fn: fn, needs: qp.needs}, nil
+}
+
+func (qp *queryParser) parseOr() (filterFn, error) {
+	left, err := qp.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for qp.acceptKeyword("or") {
+		right, err := qp.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = orFn(left, right)
+	}
+
+	return left, nil
+}
+
+func (qp *queryParser) parseAnd() (filterFn, error) {
+	left, err := qp.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if !qp.acceptKeyword("and") {
+			// adjacent terms are implicitly joined with "and"
+			if qp.skipSpace(); qp.pos >= len(qp.input) || qp.peek() == ')' || qp.peekKeyword("or") {
+				return left, nil
+			}
+		}
+
+		right, err := qp.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = andFn(left, right)
+	}
+}
+
+func (qp *queryParser) parseUnary() (filterFn, error) {
+	if qp.acceptKeyword("not") {
+		fn, err := qp.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return notFn(fn), nil
+	}
+
+	if qp.skipSpace(); qp.peek() == '(' {
+		qp.pos++
+
+		fn, err := qp.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if qp.skipSpace(); qp.peek() != ')' {
+			return nil, qp.errorf("missing closing parenthesis")
+		}
+		qp.pos++
+
+		return fn, nil
+	}
+
+	return qp.parseCondition()
+}
+
+func (qp *queryParser) parseCondition() (filterFn, error) {
+	qp.skipSpace()
+
+	start := qp.pos
+	name := qp.readWhile(func(r rune) bool { return unicode.IsLetter(r) || r == '_' })
+	if name == "" {
+		return nil, qp.errorf("expected a field name")
+	}
+
+	field, ok := queryFields[name]
+	if !ok {
+		qp.pos = start
+		return nil, qp.errorf("unknown field %q", name)
+	}
+
+	qp.skipSpace()
+	op := qp.readWhile(func(r rune) bool { return strings.ContainsRune("=!~^$*<>", r) })
+	if op == "" {
+		return nil, qp.errorf("expected an operator after %q", name)
+	}
+
+	qp.skipSpace()
+	value, err := qp.readValue()
+	if err != nil {
+		return nil, err
+	}
+
+	if field.needs != nil {
+		field.needs(&qp.needs)
+	}
+
+	if field.num != nil {
+		return qp.numCondition(name, field, op, value)
+	}
+
+	return qp.textCondition(name, field, op, value)
+}
+
+func (qp *queryParser) numCondition(name string, field queryField, op, value string) (filterFn, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, qp.errorf("invalid number %q for %s", value, name)
+	}
+
+	var cmp func(int) bool
+	switch op {
+	case "=":
+		cmp = func(v int) bool { return v == n }
+	case "!=":
+		cmp = func(v int) bool { return v != n }
+	case "<":
+		cmp = func(v int) bool { return v < n }
+	case "<=":
+		cmp = func(v int) bool { return v <= n }
+	case ">":
+		cmp = func(v int) bool { return v > n }
+	case ">=":
+		cmp = func(v int) bool { return v >= n }
+	default:
+		return nil, qp.errorf("operator %q is not supported for %s", op, name)
+	}
+
+	return func(p *process) bool {
+		v, ok := field.num(p)
+		return ok && cmp(v)
+	}, nil
+}
+
+func (qp *queryParser) textCondition(name string, field queryField, op, value string) (filterFn, error) {
+	var match func(string) bool
+	negate := false
+
+	switch op {
+	case "=", "!=":
+		match = func(s string) bool { return s == value }
+		negate = op == "!="
+	case "~", "!~":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, qp.errorf("invalid regexp for %s: %w", name, err)
+		}
+		match = re.MatchString
+		negate = op == "!~"
+	case "^=":
+		match = func(s string) bool { return strings.HasPrefix(s, value) }
+	case "$=":
+		match = func(s string) bool { return strings.HasSuffix(s, value) }
+	case "*=":
+		match = func(s string) bool { return strings.Contains(s, value) }
+	default:
+		return nil, qp.errorf("operator %q is not supported for %s", op, name)
+	}
+
+	return func(p *process) bool {
+		return slices.ContainsFunc(field.text(p), match) != negate
+	}, nil
+}
+
+func (qp *queryParser) readValue() (string, error) {
+	if qp.peek() != '"' {
+		value := qp.readWhile(func(r rune) bool { return !unicode.IsSpace(r) && r != '(' && r != ')' })
+		if value == "" {
+			return "", qp.errorf("expected a value")
+		}
+
+		return value, nil
+	}
+
+	qp.pos++
+
+	// only \" is unescaped so regexps like "\d+" can be written as they are
+	var sb strings.Builder
+	for qp.pos < len(qp.input) {
+		c := qp.input[qp.pos]
+		qp.pos++
+
+		switch {
+		case c == '"':
+			return sb.String(), nil
+		case c == '\\' && qp.peek() == '"':
+			sb.WriteByte('"')
+			qp.pos++
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return "", qp.errorf("unterminated string")
+}
+
+func (qp *queryParser) acceptKeyword(kw string) bool {
+	if !qp.peekKeyword(kw) {
+		return false
+	}
+
+	qp.pos += len(kw)
+
+	return true
+}
+
+func (qp *queryParser) peekKeyword(kw string) bool {
+	qp.skipSpace()
+
+	rest, ok := strings.CutPrefix(qp.input[qp.pos:], kw)
+	if !ok {
+		return false
+	}
+
+	return rest == "" || rest[0] == '(' || unicode.IsSpace(rune(rest[0]))
+}
+
+func (qp *queryParser) readWhile(pred func(rune) bool) string {
+	start := qp.pos
+	for qp.pos < len(qp.input) {
+		r, size := utf8.DecodeRuneInString(qp.input[qp.pos:])
+		if !pred(r) {
+			break
+		}
+
+		qp.pos += size
+	}
+
+	return qp.input[start:qp.pos]
+}
+
+func (qp *queryParser) skipSpace() {
+	qp.readWhile(unicode.IsSpace)
+}
+
+func (qp *queryParser) peek() byte {
+	if qp.pos >= len(qp.input) {
+		return 0
+	}
+
+	return qp.input[qp.pos]
+}
+
+func (qp *queryParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("query at position %d: %w", qp.pos, fmt.Errorf(format, args...))
+}
@@ -0,0 +1,98 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+)
+
+func queryTestProcesses() []*process {
+	return []*process{
+		{id: 1, attrs: attrs{name: "init", args: []string{"/sbin/init"}, numThreads: 1}},
+		{id: 2, parentID: 1, attrs: attrs{name: "java", args: []string{"java", "-Dapp=billing", "Main"}, numThreads: 42, uid: scalarUGID(1000)}},
+		{id: 3, parentID: 1, attrs: attrs{name: "bash", args: []string{"bash", "-c", `echo "hi"`}, numThreads: 1, uid: scalarUGID(0)}},
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []bool
+	}{
+		{"pid=1", []bool{true, false, false}},
+		{"pid = 1", []bool{true, false, false}},
+		{"name=java or name=bash and pid=2", []bool{false, true, false}},
+		{"(name=java or name=bash) and pid=3", []bool{false, false, true}},
+		{"name=bash pid=3", []bool{false, false, true}},
+		{"not name=init and threads>10", []bool{false, true, false}},
+		{"not (name=init or threads>10)", []bool{false, false, true}},
+		{"not not name=init", []bool{true, false, false}},
+		{"threads>10", []bool{false, true, false}},
+		{"threads >= 1", []bool{true, true, true}},
+		{"ppid!=1", []bool{true, false, false}},
+		{`args~"^-D\w+=billing$"`, []bool{false, true, false}},
+		{`args="echo \"hi\""`, []bool{false, false, true}},
+		{"args^=-D", []bool{false, true, false}},
+		{"name$=sh", []bool{false, false, true}},
+		{"cmdline*=billing", []bool{false, true, false}},
+		{`name!~"^(init|bash)$"`, []bool{false, true, false}},
+		{"uid=0", []bool{false, false, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := parseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("parseQuery(%q): %v", tt.query, err)
+			}
+
+			for i, p := range queryTestProcesses() {
+				if got := q.fn(p); got != tt.want[i] {
+					t.Errorf("pid %d: got %v, want %v", p.id, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseQueryNeeds(t *testing.T) {
+	q, err := parseQuery("uid=0 or cwd^=/tmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !q.needs.UGID || !q.needs.Workdir || q.needs.FDs {
+		t.Errorf("unexpected needs %+v", q.needs)
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "position 0: expected a field name"},
+		{"foo=1", "position 0: unknown field"},
+		{"pid", "position 3: expected an operator"},
+		{"name=", "position 5: expected a value"},
+		{"(pid=1", "position 6: missing closing parenthesis"},
+		{"pid=1 )", `position 6: unexpected ")"`},
+		{"pid~1", `position 5: operator "~" is not supported`},
+		{"threads>ten", `position 11: invalid number "ten"`},
+		{`name="java`, "position 10: unterminated string"},
+		{`name~"("`, "position 8: invalid regexp"},
+		{"pid=1 or", "position 8: expected a field name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := parseQuery(tt.query)
+			if err == nil {
+				t.Fatalf("parseQuery(%q) succeeded", tt.query)
+			}
+
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
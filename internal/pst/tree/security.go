@@ -0,0 +1,203 @@
+package tree
+
+import (
+	"fmt"
+	"math/bits"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// cap sets are nil when missing from the status file, e.g. on old kernels
+// which have no CapAmb
+type security struct {
+	capEff     *uint64
+	capPrm     *uint64
+	capBnd     *uint64
+	capAmb     *uint64
+	seccomp    int
+	noNewPrivs bool
+	label      string
+}
+
+var capNames = []string{
+	"CAP_CHOWN",
+	"CAP_DAC_OVERRIDE",
+	"CAP_DAC_READ_SEARCH",
+	"CAP_FOWNER",
+	"CAP_FSETID",
+	"CAP_KILL",
+	"CAP_SETGID",
+	"CAP_SETUID",
+	"CAP_SETPCAP",
+	"CAP_LINUX_IMMUTABLE",
+	"CAP_NET_BIND_SERVICE",
+	"CAP_NET_BROADCAST",
+	"CAP_NET_ADMIN",
+	"CAP_NET_RAW",
+	"CAP_IPC_LOCK",
+	"CAP_IPC_OWNER",
+	"CAP_SYS_MODULE",
+	"CAP_SYS_RAWIO",
+	"CAP_SYS_CHROOT",
+	"CAP_SYS_PTRACE",
+	"CAP_SYS_PACCT",
+	"CAP_SYS_ADMIN",
+	"CAP_SYS_BOOT",
+	"CAP_SYS_NICE",
+	"CAP_SYS_RESOURCE",
+	"CAP_SYS_TIME",
+	"CAP_SYS_TTY_CONFIG",
+	"CAP_MKNOD",
+	"CAP_LEASE",
+	"CAP_AUDIT_WRITE",
+	"CAP_AUDIT_CONTROL",
+	"CAP_SETFCAP",
+	"CAP_MAC_OVERRIDE",
+	"CAP_MAC_ADMIN",
+	"CAP_SYSLOG",
+	"CAP_WAKE_ALARM",
+	"CAP_BLOCK_SUSPEND",
+	"CAP_AUDIT_READ",
+	"CAP_PERFMON",
+	"CAP_BPF",
+	"CAP_CHECKPOINT_RESTORE",
+}
+
+var seccompModes = []string{"disabled", "strict", "filter"}
+
+func parseSecurity(pid int, raw map[string]string) *security {
+	sec := security{
+		capEff: parseCapMask(raw["CapEff"]),
+		capPrm: parseCapMask(raw["CapPrm"]),
+		capBnd: parseCapMask(raw["CapBnd"]),
+		capAmb: parseCapMask(raw["CapAmb"]),
+	}
+
+	sec.seccomp, _ = strconv.Atoi(raw["Seccomp"])
+	sec.noNewPrivs = raw["NoNewPrivs"] == "1"
+
+	if label, err := os.ReadFile(pidPath(pid, "attr", "current")); err == nil {
+		sec.label = strings.TrimRight(string(label), "\x00\n")
+	}
+
+	return &sec
+}
+
+func parseCapMask(s string) *uint64 {
+	mask, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return nil
+	}
+
+	return &mask
+}
+
+func hasCaps(set *uint64, mask uint64) bool {
+	return set != nil && *set&mask == mask
+}
+
+func capSetNames(mask uint64) []string {
+	var names []string
+	for mask != 0 {
+		c := bits.TrailingZeros64(mask)
+		mask &^= 1 << c
+
+		if c < len(capNames) {
+			names = append(names, capNames[c])
+		} else {
+			names = append(names, fmt.Sprintf("CAP_%d", c))
+		}
+	}
+
+	return names
+}
+
+func parseCap(name string) (int, error) {
+	if c, err := strconv.Atoi(name); err == nil && c >= 0 && c < 64 {
+		return c, nil
+	}
+
+	name = strings.ToUpper(name)
+	if !strings.HasPrefix(name, "CAP_") {
+		name = "CAP_" + name
+	}
+
+	for c, n := range capNames {
+		if n == name {
+			return c, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown capability %q", name)
+}
+
+func (s *security) String() string {
+	parts := []string{"eff=" + formatCaps(s.capEff, s.capBnd)}
+	if s.capPrm == nil || s.capEff == nil || *s.capPrm != *s.capEff {
+		parts = append(parts, "prm="+formatCaps(s.capPrm, s.capBnd))
+	}
+	if s.capBnd == nil || *s.capBnd != allCaps {
+		parts = append(parts, "bnd="+formatCaps(s.capBnd, nil))
+	}
+	if s.capAmb == nil || *s.capAmb != 0 {
+		parts = append(parts, "amb="+formatCaps(s.capAmb, s.capBnd))
+	}
+
+	if s.seccomp > 0 && s.seccomp < len(seccompModes) {
+		parts = append(parts, "seccomp="+seccompModes[s.seccomp])
+	}
+	if s.noNewPrivs {
+		parts = append(parts, "nnp")
+	}
+	if s.label != "" {
+		parts = append(parts, "label="+s.label)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// formatCaps renders an unknown set as "?" and falls back to all the known
+// capabilities when the bounding set is unknown
+func formatCaps(mask, bnd *uint64) string {
+	if mask == nil {
+		return "?"
+	}
+
+	full := allCaps
+	if bnd != nil {
+		full = *bnd
+	}
+
+	return formatCapSet(*mask, full)
+}
+
+// formatCapSet abbreviates sets close to the full (bounding) one as "all"
+// followed by the missing capabilities
+func formatCapSet(mask, full uint64) string {
+	if mask&^full != 0 {
+		full |= allCaps
+	}
+
+	switch {
+	case mask == 0:
+		return "-"
+	case mask == full:
+		return "all"
+	case mask&^full == 0 && bits.OnesCount64(mask) > bits.OnesCount64(full)/2:
+		return "all," + strings.Join(shortCapNames(full&^mask, "-"), ",")
+	}
+
+	return strings.Join(shortCapNames(mask, ""), ",")
+}
+
+func shortCapNames(mask uint64, prefix string) []string {
+	names := capSetNames(mask)
+	for i, n := range names {
+		names[i] = prefix + strings.ToLower(strings.TrimPrefix(n, "CAP_"))
+	}
+
+	return names
+}
+
+var allCaps = uint64(1)<<len(capNames) - 1
@@ -0,0 +1,87 @@
+package tree
+
+import "testing"
+
+func TestFormatCapSet(t *testing.T) {
+	tests := []struct {
+		mask, full uint64
+		want       string
+	}{
+		{0, allCaps, "-"},
+		{allCaps, allCaps, "all"},
+		{1<<0 | 1<<12, allCaps, "chown,net_admin"},
+		{allCaps &^ (1 << 21), allCaps, "all,-sys_admin"},
+		{0xff, 0xff, "all"},
+		{0x7f, 0xff, "all,-setuid"},
+		{1 << 63, allCaps, "63"},
+	}
+
+	for _, tt := range tests {
+		got := formatCapSet(tt.mask, tt.full)
+		if got != tt.want {
+			t.Errorf("formatCapSet(%#x, %#x) = %q, want %q", tt.mask, tt.full, got, tt.want)
+		}
+	}
+}
+
+func TestParseSecurity(t *testing.T) {
+	sec := parseSecurity(0, map[string]string{
+		"CapEff":     "0000000000003000",
+		"CapPrm":     "0000000000003000",
+		"CapBnd":     "000001ffffffffff",
+		"Seccomp":    "2",
+		"NoNewPrivs": "1",
+	})
+
+	if sec.capAmb != nil {
+		t.Errorf("missing CapAmb parsed as %#x", *sec.capAmb)
+	}
+	if want := "eff=net_admin,net_raw amb=? seccomp=filter nnp"; sec.String() != want {
+		t.Errorf("got %q, want %q", sec.String(), want)
+	}
+
+	sec = parseSecurity(0, map[string]string{"CapEff": "zz"})
+	if want := "eff=? prm=? bnd=? amb=?"; sec.String() != want {
+		t.Errorf("got %q, want %q", sec.String(), want)
+	}
+}
+
+func TestParseCap(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    int
+		wantErr bool
+	}{
+		{"CAP_SYS_ADMIN", 21, false},
+		{"sys_admin", 21, false},
+		{"net_raw", 13, false},
+		{"40", 40, false},
+		{"64", 0, true},
+		{"cap_foo", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseCap(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseCap(%q): error %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("parseCap(%q) = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHasCaps(t *testing.T) {
+	eff := uint64(1<<12 | 1<<13)
+
+	if !hasCaps(&eff, 1<<12|1<<13) {
+		t.Error("all required capabilities present, but not matched")
+	}
+	if hasCaps(&eff, 1<<12|1<<21) {
+		t.Error("matched with one of the required capabilities missing")
+	}
+	if hasCaps(nil, 1<<12) {
+		t.Error("unknown set matched")
+	}
+}
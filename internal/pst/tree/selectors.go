@@ -0,0 +1,123 @@
+package tree
+
+import (
+	"errors"
+	"slices"
+)
+
+type Selectors struct {
+	PIDs        []int
+	PPIDs       []int
+	SIDs        []int
+	PGIDs       []int
+	Users       []string
+	Cgroups     []string
+	Namespaces  NSSelectors
+	HasCaps     []string
+	SelfSubtree bool
+}
+
+func (t *Tree) initSelectorFn() (filterFn, ProcConfig, error) {
+	sel := &t.cfg.Select
+
+	var (
+		fns  []filterFn
+		need ProcConfig
+	)
+
+	if len(sel.PIDs) > 0 {
+		fns = append(fns, func(p *process) bool {
+			return slices.Contains(sel.PIDs, p.id)
+		})
+	}
+
+	if len(sel.PPIDs) > 0 {
+		fns = append(fns, func(p *process) bool {
+			return slices.Contains(sel.PPIDs, p.parentID)
+		})
+	}
+
+	if len(sel.SIDs) > 0 {
+		fns = append(fns, func(p *process) bool {
+			return slices.Contains(sel.SIDs, p.attrs.sid)
+		})
+	}
+
+	if len(sel.PGIDs) > 0 {
+		fns = append(fns, func(p *process) bool {
+			return slices.Contains(sel.PGIDs, p.attrs.pgid)
+		})
+	}
+
+	if len(sel.Users) > 0 {
+		uids := make([]int, len(sel.Users))
+		for i, name := range sel.Users {
+			uid, err := lookupUser(name)
+			if err != nil {
+				return nil, need, err
+			}
+
+			uids[i] = uid
+		}
+
+		need.UGID = true
+		fns = append(fns, func(p *process) bool {
+			return p.attrs.uid != nil && slices.Contains(uids, p.attrs.uid.effectiveID())
+		})
+	}
+
+	if len(sel.Cgroups) > 0 {
+		need.Cgroup = true
+		fns = append(fns, func(p *process) bool {
+			return slices.ContainsFunc(sel.Cgroups, func(cg string) bool {
+				return inCgroup(p.attrs.cgroup, cg)
+			})
+		})
+	}
+
+	if len(sel.Namespaces) > 0 {
+		need.Namespaces = true
+		fns = append(fns, sel.Namespaces.match)
+	}
+
+	if len(sel.HasCaps) > 0 {
+		var mask uint64
+		for _, name := range sel.HasCaps {
+			c, err := parseCap(name)
+			if err != nil {
+				return nil, need, err
+			}
+
+			mask |= 1 << c
+		}
+
+		need.Security = true
+		fns = append(fns, func(p *process) bool {
+			return p.attrs.security != nil && hasCaps(p.attrs.security.capEff, mask)
+		})
+	}
+
+	if sel.SelfSubtree {
+		if t.shellPID <= 0 {
+			return nil, need, errors.New("selecting the self subtree requires a live process tree")
+		}
+
+		fns = append(fns, func(p *process) bool {
+			return p.id == t.shellPID
+		})
+	}
+
+	if len(fns) == 0 {
+		return nil, need, nil
+	}
+
+	return func(p *process) bool {
+		for _, fn := range fns {
+			if !fn(p) {
+				return false
+			}
+		}
+
+		return true
+	}, need, nil
+}
@@ -0,0 +1,57 @@
+package tree
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSelectors(t *testing.T) {
+	procs := [][]string{
+		{"1", "0", "init"},
+		{"2", "1", "sshd"},
+		{"3", "2", "bash"},
+		{"4", "3", "vim"},
+		{"5", "1", "cron"},
+	}
+
+	tests := []struct {
+		sel      Selectors
+		patterns []string
+		direct   []int
+	}{
+		{Selectors{PIDs: []int{3}}, nil, []int{3}},
+		{Selectors{PIDs: []int{3, 5}}, nil, []int{3, 5}},
+		{Selectors{PPIDs: []int{1}}, nil, []int{2, 5}},
+		{Selectors{PPIDs: []int{1}, PIDs: []int{5}}, nil, []int{5}},
+		{Selectors{PPIDs: []int{1}}, []string{"sshd"}, []int{2}},
+		{Selectors{PIDs: []int{9}}, nil, nil},
+	}
+
+	for _, tt := range tests {
+		cfg := Config{Select: tt.sel}
+		tree := newTestTree(&cfg, procs...)
+
+		if err := tree.Filter(tt.patterns...); err != nil {
+			t.Fatalf("Filter(%+v): %v", tt.sel, err)
+		}
+
+		var direct []int
+		for pid := 1; pid <= len(procs); pid++ {
+			if tree.filter.matches[tree.pMap[pid]] == matchDirect {
+				direct = append(direct, pid)
+			}
+		}
+
+		if !slices.Equal(direct, tt.direct) {
+			t.Errorf("Filter(%+v, %q): direct matches %v, want %v", tt.sel, tt.patterns, direct, tt.direct)
+		}
+	}
+}
+
+func TestSelfSubtreeRequiresLiveTree(t *testing.T) {
+	tree := newTestTree(&Config{Select: Selectors{SelfSubtree: true}}, []string{"1", "0", "init"})
+
+	if err := tree.Filter(); err == nil {
+		t.Error("Filter succeeded without a shell pid")
+	}
+}
@@ -0,0 +1,338 @@
+package tree
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+)
+
+type Snapshot struct {
+	Version   int               `json:"version"`
+	Created   time.Time         `json:"created"`
+	Host      string            `json:"host,omitempty"`
+	BootTime  time.Time         `json:"bootTime,omitzero"`
+	PCfg      ProcConfig        `json:"procConfig"`
+	Processes []SnapshotProcess `json:"processes"`
+}
+
+type SnapshotProcess struct {
+	PID        int               `json:"pid"`
+	ParentPID  int               `json:"ppid"`
+	Name       string            `json:"name"`
+	Args       []string          `json:"args"`
+	Workdir    string            `json:"workdir,omitempty"`
+	Cgroup     string            `json:"cgroup,omitempty"`
+	UID        *SnapshotUGID     `json:"uid,omitempty"`
+	GID        *SnapshotUGID     `json:"gid,omitempty"`
+	Groups     []int             `json:"groups,omitempty"`
+	Security   *SnapshotSecurity `json:"security,omitempty"`
+	TracerPID  int               `json:"tracerPid,omitempty"`
+	CoreDumped bool              `json:"coreDumped,omitempty"`
+	NSPid      []string          `json:"nsPid,omitempty"`
+	Namespaces map[string]uint64 `json:"namespaces,omitempty"`
+	StartTime  uint64            `json:"startTime,omitempty"`
+	NumThreads int               `json:"numThreads,omitempty"`
+	SID        int               `json:"sid,omitempty"`
+	PGID       int               `json:"pgid,omitempty"`
+	State      string            `json:"state,omitempty"`
+	CPUTicks   uint64            `json:"cpuTicks,omitempty"`
+	CPUPercent *float64          `json:"cpuPercent,omitempty"`
+	VSize      uint64            `json:"vsize,omitempty"`
+	RSS        uint64            `json:"rss,omitempty"`
+	Threads    []SnapshotThread  `json:"threads,omitempty"`
+	FDs        []SnapshotFD      `json:"fds,omitempty"`
+	Exit       *SnapshotExit     `json:"exit,omitempty"`
+}
+
+type SnapshotUGID struct {
+	Real       int `json:"real"`
+	Effective  int `json:"effective"`
+	SavedSet   int `json:"savedSet"`
+	Filesystem int `json:"filesystem"`
+}
+
+type SnapshotSecurity struct {
+	CapEff     *uint64 `json:"capEff,omitempty"`
+	CapPrm     *uint64 `json:"capPrm,omitempty"`
+	CapBnd     *uint64 `json:"capBnd,omitempty"`
+	CapAmb     *uint64 `json:"capAmb,omitempty"`
+	Seccomp    int     `json:"seccomp"`
+	NoNewPrivs bool    `json:"noNewPrivs,omitempty"`
+	Label      string  `json:"label,omitempty"`
+}
+
+type SnapshotThread struct {
+	TID  int    `json:"tid"`
+	Name string `json:"name"`
+	Dead bool   `json:"dead,omitempty"`
+}
+
+type SnapshotFD struct {
+	Num      int    `json:"num"`
+	Link     string `json:"link"`
+	Mode     string `json:"mode,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"`
+	Peer     string `json:"peer,omitempty"`
+}
+
+type SnapshotExit struct {
+	Code       int  `json:"code"`
+	Signal     int  `json:"signal"`
+	CoreDumped bool `json:"coreDumped,omitempty"`
+}
+
+func (t *Tree) Snapshot() *Snapshot {
+	s := Snapshot{
+		Version:  snapshotVersion,
+		Created:  time.Now(),
+		BootTime: t.bootTime,
+		PCfg:     *t.loadCfg(),
+	}
+	s.Host, _ = os.Hostname()
+
+	seen := make(map[*process]bool)
+	var walk func(ps []*process)
+	walk = func(ps []*process) {
+		for _, p := range ps {
+			if seen[p] {
+				continue
+			}
+			seen[p] = true
+
+			s.Processes = append(s.Processes, p.snapshot())
+			walk(p.children)
+		}
+	}
+	walk(t.top)
+
+	slices.SortFunc(s.Processes, func(a, b SnapshotProcess) int { return a.PID - b.PID })
+
+	return &s
+}
+
+func (t *Tree) WriteSnapshot(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+
+	return enc.Encode(t.Snapshot())
+}
+
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	var s Snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("decoding snapshot: %w", err)
+	}
+
+	if s.Version < 1 || s.Version > snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d (expected 1..%d)", s.Version, snapshotVersion)
+	}
+
+	return &s, nil
+}
+
+func (t *Tree) loadSnapshot() error {
+	f, err := os.Open(t.cfg.Snapshot)
+	if err != nil {
+		return fmt.Errorf("opening snapshot: %w", err)
+	}
+	defer f.Close()
+
+	s, err := ReadSnapshot(f)
+	if err != nil {
+		return err
+	}
+
+	t.bootTime = s.BootTime
+	t.snapshotTime = s.Created
+
+	t.pMap = make(map[int]*process, len(s.Processes))
+	for i := range s.Processes {
+		p := s.Processes[i].process()
+		t.pMap[p.id] = p
+	}
+
+	return nil
+}
+
+func (p *process) snapshot() SnapshotProcess {
+	sp := SnapshotProcess{
+		PID:        p.id,
+		ParentPID:  p.parentID,
+		Name:       p.attrs.name,
+		Args:       p.attrs.args,
+		Workdir:    p.attrs.workdir,
+		Cgroup:     p.attrs.cgroup,
+		UID:        snapshotUGID(p.attrs.uid),
+		GID:        snapshotUGID(p.attrs.gid),
+		Groups:     p.attrs.groups,
+		TracerPID:  p.attrs.tracer,
+		CoreDumped: p.coreDumped,
+		NSPid:      p.attrs.nsPid,
+		Namespaces: p.attrs.ns,
+		StartTime:  p.attrs.startTime,
+		NumThreads: p.attrs.numThreads,
+		SID:        p.attrs.sid,
+		PGID:       p.attrs.pgid,
+		State:      p.attrs.state,
+		CPUTicks:   p.attrs.cpuTicks,
+		CPUPercent: p.attrs.cpuPercent,
+		VSize:      p.attrs.vsize,
+		RSS:        p.attrs.rss,
+	}
+
+	if sec := p.attrs.security; sec != nil {
+		sp.Security = &SnapshotSecurity{
+			CapEff:     sec.capEff,
+			CapPrm:     sec.capPrm,
+			CapBnd:     sec.capBnd,
+			CapAmb:     sec.capAmb,
+			Seccomp:    sec.seccomp,
+			NoNewPrivs: sec.noNewPrivs,
+			Label:      sec.label,
+		}
+	}
+
+	for _, thr := range p.threads {
+		sp.Threads = append(sp.Threads, SnapshotThread{
+			TID:  thr.id,
+			Name: thr.name,
+			Dead: thr.dead,
+		})
+	}
+
+	for _, fd := range p.fds {
+		sp.FDs = append(sp.FDs, SnapshotFD{
+			Num:      fd.num,
+			Link:     fd.link,
+			Mode:     string(fd.mode),
+			Endpoint: fd.endpoint,
+			Peer:     fd.peer,
+		})
+	}
+
+	if p.exit != nil {
+		sp.Exit = &SnapshotExit{
+			Code:       p.exit.code,
+			Signal:     p.exit.signal,
+			CoreDumped: p.exit.coreDumped,
+		}
+	}
+
+	return sp
+}
+
+func (sp *SnapshotProcess) process() *process {
+	p := process{
+		id:       sp.PID,
+		parentID: sp.ParentPID,
+		attrs: attrs{
+			name:       sp.Name,
+			args:       sp.Args,
+			workdir:    sp.Workdir,
+			cgroup:     sp.Cgroup,
+			uid:        sp.UID.ugid(),
+			gid:        sp.GID.ugid(),
+			groups:     sp.Groups,
+			tracer:     sp.TracerPID,
+			nsPid:      sp.NSPid,
+			ns:         sp.Namespaces,
+			startTime:  sp.StartTime,
+			numThreads: sp.NumThreads,
+			sid:        sp.SID,
+			pgid:       sp.PGID,
+			state:      sp.State,
+			cpuTicks:   sp.CPUTicks,
+			cpuPercent: sp.CPUPercent,
+			vsize:      sp.VSize,
+			rss:        sp.RSS,
+		},
+	}
+
+	p.coreDumped = sp.CoreDumped
+
+	if sec := sp.Security; sec != nil {
+		p.attrs.security = &security{
+			capEff:     sec.CapEff,
+			capPrm:     sec.CapPrm,
+			capBnd:     sec.CapBnd,
+			capAmb:     sec.CapAmb,
+			seccomp:    sec.Seccomp,
+			noNewPrivs: sec.NoNewPrivs,
+			label:      sec.Label,
+		}
+	}
+
+	for _, thr := range sp.Threads {
+		p.threads = append(p.threads, &thread{
+			id:   thr.TID,
+			name: thr.Name,
+			dead: thr.Dead,
+		})
+	}
+
+	for _, fd := range sp.FDs {
+		p.fds = append(p.fds, fileDes{
+			num:      fd.Num,
+			link:     fd.Link,
+			mode:     fdMode(fd.Mode),
+			endpoint: fd.Endpoint,
+			peer:     fd.Peer,
+		})
+	}
+
+	if sp.Exit != nil {
+		p.exit = &exitStatus{
+			code:       sp.Exit.Code,
+			signal:     sp.Exit.Signal,
+			coreDumped: sp.Exit.CoreDumped,
+		}
+	}
+
+	return &p
+}
+
+func (s *SnapshotUGID) ugid() ugid {
+	if s == nil {
+		return nil
+	}
+
+	if s.Real == s.Effective && s.Real == s.SavedSet && s.Real == s.Filesystem {
+		return scalarUGID(s.Real)
+	}
+
+	return multiUGID{
+		real:       s.Real,
+		effective:  s.Effective,
+		savedSet:   s.SavedSet,
+		filesystem: s.Filesystem,
+	}
+}
+
+func snapshotUGID(u ugid) *SnapshotUGID {
+	switch u := u.(type) {
+	case scalarUGID:
+		return &SnapshotUGID{
+			Real:       int(u),
+			Effective:  int(u),
+			SavedSet:   int(u),
+			Filesystem: int(u),
+		}
+	case multiUGID:
+		return &SnapshotUGID{
+			Real:       u.real,
+			Effective:  u.effective,
+			SavedSet:   u.savedSet,
+			Filesystem: u.filesystem,
+		}
+	default:
+		return nil
+	}
+}
+
+const (
+	snapshotVersion = 1
+)
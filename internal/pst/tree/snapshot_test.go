@@ -0,0 +1,112 @@
+package tree
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	tree := newTestTree(&Config{},
+		[]string{"1", "0", "init"},
+		[]string{"2", "1", "sshd", "-D"},
+		[]string{"3", "2", "bash", "-l"},
+		[]string{"4", "1", "cron"},
+	)
+
+	eff := uint64(1 << 12)
+	bash := tree.pMap[3]
+	bash.attrs.uid = eventUGID(1000, 0)
+	bash.attrs.gid = scalarUGID(100)
+	bash.attrs.security = &security{capEff: &eff}
+	bash.threads = []*thread{{id: 5, name: "worker", dead: true}}
+	bash.fds = []fileDes{{num: 3, link: "socket:[123]", endpoint: "tcp 127.0.0.1:22"}}
+	tree.pMap[4].exit = &exitStatus{signal: 11, coreDumped: true}
+
+	var buf bytes.Buffer
+	if err := tree.WriteSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := ReadSnapshot(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadSnapshot: %v", err)
+	}
+	if want := tree.Snapshot(); !reflect.DeepEqual(s.Processes, want.Processes) {
+		t.Errorf("processes changed on the round trip:\ngot  %+v\nwant %+v", s.Processes, want.Processes)
+	}
+
+	loaded, err := Build(&Config{Snapshot: path, Ancestors: DepthAll, Descendants: DepthAll})
+	if err != nil {
+		t.Fatalf("Build from snapshot: %v", err)
+	}
+
+	tests := []struct {
+		pid, ppid int
+		cmdline   string
+	}{
+		{1, 0, "init"},
+		{2, 1, "sshd -D"},
+		{3, 2, "bash -l"},
+		{4, 1, "cron"},
+	}
+
+	for _, tt := range tests {
+		p := loaded.pMap[tt.pid]
+		if p == nil {
+			t.Errorf("pid %d not loaded", tt.pid)
+			continue
+		}
+		if p.parentID != tt.ppid || p.attrs.cmdline() != tt.cmdline {
+			t.Errorf("pid %d: got ppid %d %q, want %d %q", tt.pid, p.parentID, p.attrs.cmdline(), tt.ppid, tt.cmdline)
+		}
+	}
+
+	p := loaded.pMap[3]
+	if p.attrs.uid == nil || p.attrs.uid.id() != "(r:1000 e:0 ss:0 fs:0)" || p.attrs.gid.id() != "100" {
+		t.Errorf("ids not restored: %v %v", p.attrs.uid, p.attrs.gid)
+	}
+	if p.attrs.security == nil || p.attrs.security.capEff == nil || *p.attrs.security.capEff != eff {
+		t.Errorf("security not restored: %+v", p.attrs.security)
+	}
+	if len(p.threads) != 1 || !p.threads[0].dead || len(p.fds) != 1 || p.fds[0].endpoint != "tcp 127.0.0.1:22" {
+		t.Errorf("threads or fds not restored: %+v %+v", p.threads, p.fds)
+	}
+	if e := loaded.pMap[4].exit; e == nil || e.String() != "*SIGSEGV core*" {
+		t.Errorf("exit not restored: %v", e)
+	}
+}
+
+func TestReadSnapshotErrors(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{`{"version": 0, "processes": []}`, "unsupported snapshot version 0"},
+		{`{"processes": []}`, "unsupported snapshot version 0"},
+		{`{"version": 99, "processes": []}`, "unsupported snapshot version 99"},
+		{`{"version": 1, "processes": [`, "decoding snapshot"},
+		{`{"version": "1"}`, "decoding snapshot"},
+		{``, "decoding snapshot"},
+	}
+
+	for _, tt := range tests {
+		_, err := ReadSnapshot(strings.NewReader(tt.data))
+		if err == nil {
+			t.Errorf("ReadSnapshot(%q) succeeded", tt.data)
+			continue
+		}
+
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ReadSnapshot(%q): got error %q, want it to contain %q", tt.data, err, tt.want)
+		}
+	}
+}
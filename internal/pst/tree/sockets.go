@@ -0,0 +1,257 @@
+package tree
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kevwargo/go-pst/internal/unixdiag"
+	"golang.org/x/sys/unix"
+)
+
+type socketTable map[string]string
+
+func (t *Tree) resolveSockets(ps ...*process) {
+	if !t.loadCfg().FDs {
+		return
+	}
+
+	tables := make(map[string]socketTable)
+	var unixPeers map[uint64]uint64
+
+	for _, p := range ps {
+		var table socketTable
+
+		for i, fd := range p.fds {
+			if fdKind(fd.link) != fdKindSocket {
+				continue
+			}
+
+			if table == nil {
+				table = netnsSocketTable(p.id, tables)
+			}
+
+			p.fds[i].endpoint = table[fd.link]
+
+			if !strings.HasPrefix(p.fds[i].endpoint, "unix") {
+				continue
+			}
+
+			if unixPeers == nil {
+				// sock_diag only reports sockets from our own network namespace,
+				// so peers of sockets in other namespaces stay unresolved
+				if unixPeers, _ = unixdiag.Peers(); unixPeers == nil {
+					unixPeers = make(map[uint64]uint64)
+				}
+			}
+
+			if peer, ok := unixPeers[linkInode(fd.link)]; ok {
+				p.fds[i].peer = fmt.Sprintf("socket:[%d]", peer)
+			}
+		}
+	}
+}
+
+func linkInode(link string) uint64 {
+	_, rest, _ := strings.Cut(link, "[")
+	inode, _ := strconv.ParseUint(strings.TrimSuffix(rest, "]"), 10, 64)
+
+	return inode
+}
+
+func netnsSocketTable(pid int, tables map[string]socketTable) socketTable {
+	netns, err := os.Readlink(pidPath(pid, "ns", "net"))
+	if err != nil {
+		netns = fmt.Sprintf("pid:%d", pid)
+	}
+
+	if table, ok := tables[netns]; ok {
+		return table
+	}
+
+	table := make(socketTable)
+	for _, src := range socketSources {
+		// a missing or unreadable table just leaves its sockets unresolved
+		table.load(pid, src)
+	}
+
+	tables[netns] = table
+
+	return table
+}
+
+type socketSource struct {
+	file  string
+	inode int
+	parse func(fields []string) string
+}
+
+var socketSources = []socketSource{
+	{file: "tcp", inode: 9, parse: parseInetSocket("tcp")},
+	{file: "tcp6", inode: 9, parse: parseInetSocket("tcp6")},
+	{file: "udp", inode: 9, parse: parseInetSocket("udp")},
+	{file: "udp6", inode: 9, parse: parseInetSocket("udp6")},
+	{file: "unix", inode: 6, parse: parseUnixSocket},
+	{file: "netlink", inode: 9, parse: parseNetlinkSocket},
+	{file: "packet", inode: 8, parse: parsePacketSocket},
+}
+
+func (st socketTable) load(pid int, src socketSource) error {
+	f, err := os.Open(pidPath(pid, "net", src.file))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // skip the header
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) <= src.inode {
+			continue
+		}
+
+		st[fmt.Sprintf("socket:[%s]", fields[src.inode])] = src.parse(fields)
+	}
+
+	return scanner.Err()
+}
+
+func parseInetSocket(proto string) func([]string) string {
+	return func(fields []string) string {
+		local := parseInetAddr(fields[1])
+		remote := parseInetAddr(fields[2])
+
+		state, _ := strconv.ParseUint(fields[3], 16, 8)
+
+		if strings.HasPrefix(proto, "tcp") {
+			if state == tcpListen {
+				return fmt.Sprintf("%s %s %s", proto, local, tcpStates[state])
+			}
+
+			return fmt.Sprintf("%s %s -> %s %s", proto, local, remote, tcpStates[state])
+		}
+
+		if strings.HasSuffix(remote, ":0") {
+			return fmt.Sprintf("%s %s", proto, local)
+		}
+
+		return fmt.Sprintf("%s %s -> %s", proto, local, remote)
+	}
+}
+
+func parseInetAddr(raw string) string {
+	addrHex, portHex, ok := strings.Cut(raw, ":")
+	if !ok {
+		return raw
+	}
+
+	port, err := strconv.ParseUint(portHex, 16, 16)
+	if err != nil {
+		return raw
+	}
+
+	addrBytes, err := hex.DecodeString(addrHex)
+	if err != nil {
+		return raw
+	}
+
+	// the kernel prints addresses as a sequence of 32-bit words read in host
+	// order, so their in-memory layout gives back the network order bytes
+	for i := 0; i+4 <= len(addrBytes); i += 4 {
+		w := addrBytes[i : i+4]
+		binary.NativeEndian.PutUint32(w, binary.BigEndian.Uint32(w))
+	}
+
+	addr, ok := netip.AddrFromSlice(addrBytes)
+	if !ok {
+		return raw
+	}
+
+	return netip.AddrPortFrom(addr.Unmap(), uint16(port)).String()
+}
+
+func parseUnixSocket(fields []string) string {
+	typ, _ := strconv.ParseUint(fields[4], 16, 16)
+
+	desc := "unix"
+	if name, ok := unixSocketTypes[typ]; ok {
+		desc += " " + name
+	}
+
+	if len(fields) > 7 {
+		desc += " " + fields[7]
+	}
+
+	return desc
+}
+
+func parseNetlinkSocket(fields []string) string {
+	proto, _ := strconv.Atoi(fields[1])
+
+	name, ok := netlinkProtocols[proto]
+	if !ok {
+		name = strconv.Itoa(proto)
+	}
+
+	return fmt.Sprintf("netlink %s portid:%s", name, fields[2])
+}
+
+func parsePacketSocket(fields []string) string {
+	return fmt.Sprintf("packet proto:0x%s iface:%s", fields[3], fields[4])
+}
+
+const (
+	tcpListen = 0x0a
+)
+
+var tcpStates = map[uint64]string{
+	0x01: "ESTABLISHED",
+	0x02: "SYN_SENT",
+	0x03: "SYN_RECV",
+	0x04: "FIN_WAIT1",
+	0x05: "FIN_WAIT2",
+	0x06: "TIME_WAIT",
+	0x07: "CLOSE",
+	0x08: "CLOSE_WAIT",
+	0x09: "LAST_ACK",
+	0x0a: "LISTEN",
+	0x0b: "CLOSING",
+	0x0c: "NEW_SYN_RECV",
+}
+
+var unixSocketTypes = map[uint64]string{
+	unix.SOCK_STREAM:    "stream",
+	unix.SOCK_DGRAM:     "dgram",
+	unix.SOCK_SEQPACKET: "seqpacket",
+}
+
+var netlinkProtocols = map[int]string{
+	unix.NETLINK_ROUTE:          "route",
+	unix.NETLINK_USERSOCK:       "usersock",
+	unix.NETLINK_FIREWALL:       "firewall",
+	unix.NETLINK_SOCK_DIAG:      "sock_diag",
+	unix.NETLINK_NFLOG:          "nflog",
+	unix.NETLINK_XFRM:           "xfrm",
+	unix.NETLINK_SELINUX:        "selinux",
+	unix.NETLINK_ISCSI:          "iscsi",
+	unix.NETLINK_AUDIT:          "audit",
+	unix.NETLINK_FIB_LOOKUP:     "fib_lookup",
+	unix.NETLINK_CONNECTOR:      "connector",
+	unix.NETLINK_NETFILTER:      "netfilter",
+	unix.NETLINK_IP6_FW:         "ip6_fw",
+	unix.NETLINK_DNRTMSG:        "dnrtmsg",
+	unix.NETLINK_KOBJECT_UEVENT: "kobject_uevent",
+	unix.NETLINK_GENERIC:        "generic",
+	unix.NETLINK_SCSITRANSPORT:  "scsitransport",
+	unix.NETLINK_ECRYPTFS:       "ecryptfs",
+	unix.NETLINK_RDMA:           "rdma",
+	unix.NETLINK_CRYPTO:         "crypto",
+	unix.NETLINK_SMC:            "smc",
+}
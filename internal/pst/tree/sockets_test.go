@@ -0,0 +1,68 @@
+package tree
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+// procNetAddr formats the address like /proc/net/{tcp,udp}*, which print each
+// 32-bit word of it as a host-order integer
+func procNetAddr(s string) string {
+	ap := netip.MustParseAddrPort(s)
+
+	raw := ap.Addr().AsSlice()
+	var sb strings.Builder
+	for i := 0; i < len(raw); i += 4 {
+		fmt.Fprintf(&sb, "%08X", binary.NativeEndian.Uint32(raw[i:i+4]))
+	}
+
+	return fmt.Sprintf("%s:%04X", sb.String(), ap.Port())
+}
+
+func TestParseInetAddr(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{procNetAddr("127.0.0.1:80"), "127.0.0.1:80"},
+		{procNetAddr("0.0.0.0:8080"), "0.0.0.0:8080"},
+		{procNetAddr("192.168.1.1:54321"), "192.168.1.1:54321"},
+		{procNetAddr("[::1]:22"), "[::1]:22"},
+		{procNetAddr("[::ffff:127.0.0.1]:443"), "127.0.0.1:443"},
+		{procNetAddr("[2001:db8::1]:53"), "[2001:db8::1]:53"},
+		{"0100007F", "0100007F"},
+		{"0100007F:XYZ", "0100007F:XYZ"},
+		{"0100zz7F:0050", "0100zz7F:0050"},
+		{"01007F:0050", "01007F:0050"},
+	}
+
+	for _, tt := range tests {
+		if got := parseInetAddr(tt.raw); got != tt.want {
+			t.Errorf("parseInetAddr(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestParseInetAddrLittleEndian(t *testing.T) {
+	if binary.NativeEndian.Uint16([]byte{1, 0}) != 1 {
+		t.Skip("not a little-endian host")
+	}
+
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"0100007F:0050", "127.0.0.1:80"},
+		{"0101A8C0:D431", "192.168.1.1:54321"},
+		{"B80D0120000000000000000001000000:0035", "[2001:db8::1]:53"},
+	}
+
+	for _, tt := range tests {
+		if got := parseInetAddr(tt.raw); got != tt.want {
+			t.Errorf("parseInetAddr(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
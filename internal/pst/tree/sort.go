@@ -0,0 +1,119 @@
+package tree
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type SortOrder string
+
+type comparator func(t *Tree, a, b *process) int
+
+var comparators = map[SortOrder]comparator{
+	"weight": func(t *Tree, a, b *process) int {
+		return t.weights[a] - t.weights[b]
+	},
+	"pid": func(_ *Tree, a, b *process) int {
+		return a.id - b.id
+	},
+	"start": func(_ *Tree, a, b *process) int {
+		return cmp.Compare(a.attrs.startTime, b.attrs.startTime)
+	},
+	"name": func(_ *Tree, a, b *process) int {
+		return strings.Compare(a.attrs.name, b.attrs.name)
+	},
+	// heaviest subtrees first
+	"cpu": func(t *Tree, a, b *process) int {
+		return cmp.Compare(t.totals[b].cpu, t.totals[a].cpu)
+	},
+	"rss": func(t *Tree, a, b *process) int {
+		return cmp.Compare(t.totals[b].rss, t.totals[a].rss)
+	},
+	"user": func(t *Tree, a, b *process) int {
+		return strings.Compare(t.userSortKey(a), t.userSortKey(b))
+	},
+}
+
+var sortOrders = []SortOrder{"weight", "pid", "start", "name", "cpu", "rss", "user"}
+
+func (s *SortOrder) Set(v string) error {
+	if _, ok := comparators[SortOrder(v)]; !ok {
+		return fmt.Errorf("invalid sort order %q: must be one of %s", v, s.Type())
+	}
+
+	*s = SortOrder(v)
+
+	return nil
+}
+
+func (s SortOrder) String() string {
+	return string(s)
+}
+
+func (s SortOrder) Type() string {
+	names := make([]string, len(sortOrders))
+	for i, o := range sortOrders {
+		names[i] = string(o)
+	}
+
+	return strings.Join(names, "|")
+}
+
+func (t *Tree) compare(a, b *process) int {
+	diff := t.comparator(t, a, b)
+	if t.cfg.Reverse {
+		diff = -diff
+	}
+
+	if diff != 0 {
+		return diff
+	}
+
+	return a.id - b.id
+}
+
+func (t *Tree) initComparator() {
+	if t.cfg.Sort == "" {
+		t.cfg.Sort = SortDefault
+	}
+
+	t.comparator = comparators[t.cfg.Sort]
+}
+
+func (t *Tree) CycleSort() {
+	old := *t.loadCfg()
+
+	i := slices.Index(sortOrders, t.cfg.Sort)
+	t.cfg.Sort = sortOrders[(i+1)%len(sortOrders)]
+	t.initComparator()
+
+	// some orders compare attributes which aren't loaded by default
+	if *t.loadCfg() != old {
+		t.reload()
+		return
+	}
+
+	t.refreshView()
+}
+
+func (t *Tree) ToggleReverse() {
+	t.cfg.Reverse = !t.cfg.Reverse
+	t.refreshView()
+}
+
+// userSortKey names the user the same way as the uid:gid column
+func (t *Tree) userSortKey(p *process) string {
+	if p.attrs.uid == nil {
+		return ""
+	}
+
+	userName, _ := t.idNamers()
+
+	return userName(p.attrs.uid.effectiveID())
+}
+
+const (
+	SortDefault SortOrder = "weight"
+)
@@ -0,0 +1,89 @@
+package tree
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSortOrderSet(t *testing.T) {
+	for _, o := range sortOrders {
+		var s SortOrder
+		if err := s.Set(string(o)); err != nil || s != o {
+			t.Errorf("Set(%q) = %q, %v", o, s, err)
+		}
+	}
+
+	for _, v := range []string{"", "memory", "PID"} {
+		var s SortOrder
+		if err := s.Set(v); err == nil {
+			t.Errorf("Set(%q) succeeded", v)
+		}
+	}
+}
+
+func TestSortOrdersHaveComparators(t *testing.T) {
+	for _, o := range sortOrders {
+		if comparators[o] == nil {
+			t.Errorf("no comparator for %q", o)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	procs := [][]string{
+		{"1", "0", "init"},
+		{"4", "1", "bravo"},
+		{"2", "1", "charlie"},
+		{"3", "1", "alpha"},
+	}
+
+	tests := []struct {
+		order   SortOrder
+		reverse bool
+		want    []int
+	}{
+		{"pid", false, []int{2, 3, 4}},
+		{"pid", true, []int{4, 3, 2}},
+		{"name", false, []int{3, 4, 2}},
+		{"name", true, []int{2, 4, 3}},
+	}
+
+	for _, tt := range tests {
+		cfg := Config{Sort: tt.order, Reverse: tt.reverse}
+		tree := newTestTree(&cfg, procs...)
+		tree.refreshView()
+
+		var got []int
+		for _, c := range tree.pMap[1].children {
+			got = append(got, c.id)
+		}
+
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("sort %s reverse=%v: got %v, want %v", tt.order, tt.reverse, got, tt.want)
+		}
+	}
+}
+
+func TestUserSortKey(t *testing.T) {
+	p := &process{id: 1, attrs: attrs{uid: scalarUGID(0)}}
+
+	tests := []struct {
+		cfg  Config
+		want string
+	}{
+		{Config{}, lookupUserName(0)},
+		{Config{NumericIDs: true}, "0"},
+		{Config{Snapshot: "old.json"}, "0"},
+	}
+
+	for _, tt := range tests {
+		tree := Tree{cfg: &tt.cfg}
+		if got := tree.userSortKey(p); got != tt.want {
+			t.Errorf("%+v: got %q, want %q", tt.cfg, got, tt.want)
+		}
+	}
+
+	if got := (&Tree{cfg: &Config{}}).userSortKey(&process{}); got != "" {
+		t.Errorf("unknown uid: got %q", got)
+	}
+}
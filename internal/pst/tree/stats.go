@@ -0,0 +1,269 @@
+package tree
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+type procStats struct {
+	cpu float64
+	rss uint64
+	vsz uint64
+}
+
+// SamplesStats reports whether RefreshStats has anything to update
+func (t *Tree) SamplesStats() bool {
+	return t.Live() && t.loadCfg().Stats
+}
+
+func (t *Tree) RefreshStats() {
+	if !t.SamplesStats() {
+		return
+	}
+
+	t.walkVisible(t.top, nil, func(p, _ *process) {
+		if p.exit == nil {
+			p.resampleStats()
+		}
+	})
+
+	t.refreshView()
+}
+
+func (t *Tree) sampleCPU() error {
+	time.Sleep(t.cfg.CPUInterval)
+
+	for _, p := range t.pMap {
+		if err := p.resampleStats(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *process) resampleStats() error {
+	prevTicks, prevSampled := p.attrs.cpuTicks, p.attrs.sampled
+
+	// a process which exits in the meantime has no sample
+	stat, err := readStatFields(p.id)
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, unix.ESRCH) {
+		p.attrs.cpuPercent = nil
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if err := p.attrs.parseStats(stat); err != nil {
+		return fmt.Errorf("invalid stat for Pid %d: %w", p.id, err)
+	}
+
+	if !prevSampled.IsZero() {
+		p.attrs.sampleCPU(prevTicks, p.attrs.sampled.Sub(prevSampled))
+	}
+
+	return nil
+}
+
+func (a *attrs) parseStats(stat []string) (err error) {
+	if len(stat) <= statRSS {
+		return nil
+	}
+
+	a.state = stat[statState]
+
+	utime, err := strconv.ParseUint(stat[statUTime], 10, 64)
+	if err != nil {
+		return err
+	}
+	stime, err := strconv.ParseUint(stat[statSTime], 10, 64)
+	if err != nil {
+		return err
+	}
+	a.cpuTicks = utime + stime
+
+	a.vsize, err = strconv.ParseUint(stat[statVSize], 10, 64)
+	if err != nil {
+		return err
+	}
+
+	rssPages, err := strconv.ParseUint(stat[statRSS], 10, 64)
+	if err != nil {
+		return err
+	}
+	a.rss = rssPages * uint64(os.Getpagesize())
+	a.sampled = time.Now()
+
+	return nil
+}
+
+func (a *attrs) sampleCPU(prevTicks uint64, interval time.Duration) {
+	if a.cpuTicks < prevTicks || interval <= 0 {
+		return
+	}
+
+	cpu := float64(a.cpuTicks-prevTicks) / float64(clockTicks()) / interval.Seconds() * 100
+	a.cpuPercent = &cpu
+}
+
+func (t *Tree) stats(p *process) procStats {
+	s := procStats{
+		rss: p.attrs.rss,
+		vsz: p.attrs.vsize,
+	}
+
+	if p.attrs.cpuPercent != nil {
+		s.cpu = *p.attrs.cpuPercent
+	} else if elapsed := t.elapsed(p); elapsed > 0 {
+		s.cpu = float64(p.attrs.cpuTicks) / float64(clockTicks()) / elapsed.Seconds() * 100
+	}
+
+	return s
+}
+
+// sumStats computes the totals of the visible subtrees of ps, so that they
+// match what's shown. It runs before sorting, so passthrough processes are
+// flattened here rather than with visibleChildren, which sorts them
+func (t *Tree) sumStats(ps []*process) procStats {
+	var total procStats
+
+	for _, p := range ps {
+		var s procStats
+
+		switch {
+		case p.exit != nil:
+			continue
+		case t.isProcVisible(p):
+			s = t.stats(p)
+			s.add(t.sumStats(p.children))
+			t.totals[p] = s
+		case t.isPassthrough(p):
+			s = t.sumStats(p.children)
+		}
+
+		total.add(s)
+	}
+
+	return total
+}
+
+func (s *procStats) add(o procStats) {
+	s.cpu += o.cpu
+	s.rss += o.rss
+	s.vsz += o.vsz
+}
+
+func (t *Tree) startTime(p *process) time.Time {
+	if t.bootTime.IsZero() || p.attrs.startTime == 0 {
+		return time.Time{}
+	}
+
+	return t.bootTime.Add(time.Duration(p.attrs.startTime) * time.Second / time.Duration(clockTicks()))
+}
+
+func (t *Tree) elapsed(p *process) time.Duration {
+	start := t.startTime(p)
+	if start.IsZero() {
+		return 0
+	}
+
+	if !t.Live() {
+		return t.snapshotTime.Sub(start)
+	}
+
+	return time.Since(start)
+}
+
+func (t *Tree) renderStats(p *process) string {
+	s := t.stats(p)
+
+	var totals string
+	if len(t.visibleChildren(p.children)) > 0 {
+		total := t.totals[p]
+		totals = fmt.Sprintf("%6.1f %7s", total.cpu, formatSize(total.rss))
+	}
+
+	return fmt.Sprintf(statsLayout,
+		p.attrs.state, fmt.Sprintf("%.1f", s.cpu), formatSize(s.rss), formatSize(s.vsz),
+		formatStart(t.startTime(p)), formatElapsed(t.elapsed(p)), totals)
+}
+
+func statsHeader() string {
+	return fmt.Sprintf(statsLayout, "S", "%CPU", "RSS", "VSZ", "START", "ELAPSED", fmt.Sprintf("%6s %7s", "TCPU", "TRSS"))
+}
+
+func formatSize(n uint64) string {
+	const unit = 1024
+
+	if n < unit {
+		return strconv.FormatUint(n, 10)
+	}
+
+	value := float64(n)
+	suffix := "KMGTPE"
+	i := -1
+	for value >= unit && i < len(suffix)-1 {
+		value /= unit
+		i++
+	}
+
+	return fmt.Sprintf("%.1f%c", value, suffix[i])
+}
+
+func formatStart(start time.Time) string {
+	if start.IsZero() {
+		return "-"
+	}
+
+	if now := time.Now(); now.Year() == start.Year() && now.YearDay() == start.YearDay() {
+		return start.Format("15:04")
+	}
+
+	return start.Format("Jan02")
+}
+
+func formatElapsed(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+
+	secs := int(d.Seconds())
+	days, secs := secs/86400, secs%86400
+	hours, secs := secs/3600, secs%3600
+	mins, secs := secs/60, secs%60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%d-%02d:%02d:%02d", days, hours, mins, secs)
+	case hours > 0:
+		return fmt.Sprintf("%02d:%02d:%02d", hours, mins, secs)
+	default:
+		return fmt.Sprintf("%02d:%02d", mins, secs)
+	}
+}
+
+// clockTicks returns USER_HZ, the unit of the time fields in /proc/PID/stat
+var clockTicks = sync.OnceValue(func() uint64 {
+	if auxv, err := unix.Auxv(); err == nil {
+		for _, kv := range auxv {
+			if kv[0] == atClkTck && kv[1] > 0 {
+				return uint64(kv[1])
+			}
+		}
+	}
+
+	return defaultClockTicks
+})
+
+const (
+	atClkTck          = 17
+	defaultClockTicks = 100
+
+	statsLayout = "%-1s %5s %7s %7s %5s %11s %14s "
+)
@@ -0,0 +1,123 @@
+package tree
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		n    uint64
+		want string
+	}{
+		{0, "0"},
+		{1023, "1023"},
+		{1024, "1.0K"},
+		{1536, "1.5K"},
+		{8 << 20, "8.0M"},
+		{5 << 30, "5.0G"},
+		{1 << 60, "1.0E"},
+	}
+
+	for _, tt := range tests {
+		if got := formatSize(tt.n); got != tt.want {
+			t.Errorf("formatSize(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "-"},
+		{-time.Second, "-"},
+		{59 * time.Second, "00:59"},
+		{61*time.Minute + 5*time.Second, "01:01:05"},
+		{49*time.Hour + 3*time.Minute, "2-01:03:00"},
+	}
+
+	for _, tt := range tests {
+		if got := formatElapsed(tt.d); got != tt.want {
+			t.Errorf("formatElapsed(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestSumStatsVisibleOnly(t *testing.T) {
+	cfg := Config{Ancestors: DepthAll, Descendants: 0}
+	tree := newTestTree(&cfg,
+		[]string{"1", "0", "init"},
+		[]string{"2", "1", "server"},
+		[]string{"3", "2", "worker"},
+	)
+	for pid, rss := range map[int]uint64{1: 1, 2: 10, 3: 100} {
+		tree.pMap[pid].attrs.rss = rss
+	}
+
+	if err := tree.Filter("server"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := tree.totals[tree.pMap[1]].rss; got != 11 {
+		t.Errorf("init total rss = %d, want 11", got)
+	}
+	if got := tree.totals[tree.pMap[2]].rss; got != 10 {
+		t.Errorf("server total rss = %d, want 10", got)
+	}
+	if _, ok := tree.totals[tree.pMap[3]]; ok {
+		t.Error("hidden worker has a total")
+	}
+}
+
+func TestClockTicks(t *testing.T) {
+	if clockTicks() == 0 {
+		t.Error("clockTicks() = 0")
+	}
+}
+
+func TestResampleStatsGone(t *testing.T) {
+	cpu := 12.5
+	p := &process{id: 999999999, attrs: attrs{cpuPercent: &cpu}}
+
+	if err := p.resampleStats(); err != nil {
+		t.Fatalf("resampling an exited process: %v", err)
+	}
+	if p.attrs.cpuPercent != nil {
+		t.Errorf("exited process kept its sample %v", *p.attrs.cpuPercent)
+	}
+}
+
+func TestSumStatsPassthrough(t *testing.T) {
+	cfg := Config{Ancestors: 0, Descendants: 0, Sort: "rss", PCfg: ProcConfig{Stats: true}}
+	tree := newTestTree(&cfg,
+		[]string{"1", "0", "init"},
+		[]string{"2", "1", "shell"},
+		[]string{"3", "2", "job-small"},
+		[]string{"4", "2", "job-big"},
+		[]string{"5", "1", "job-mid"},
+	)
+	for pid, rss := range map[int]uint64{1: 1000, 2: 1000, 3: 10, 4: 100, 5: 50} {
+		tree.pMap[pid].attrs.rss = rss
+	}
+
+	if err := tree.Filter("job"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pid := range []int{1, 2} {
+		if _, ok := tree.totals[tree.pMap[pid]]; ok {
+			t.Errorf("passthrough pid %d has a total", pid)
+		}
+	}
+
+	var order []int
+	for _, p := range tree.visibleChildren(tree.top) {
+		order = append(order, p.id)
+	}
+	if want := []int{4, 5, 3}; !slices.Equal(order, want) {
+		t.Errorf("flattened order %v, want %v", order, want)
+	}
+}
@@ -3,6 +3,7 @@ package tree
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"slices"
 	"strings"
@@ -15,34 +16,81 @@ import (
 )
 
 type Config struct {
-	PCfg          ProcConfig
-	FullMatch     bool
-	ShowDead      bool
-	Truncate      int
-	FitTermWidth  bool
-	FitTermHeight bool
+	PCfg           ProcConfig
+	Snapshot       string
+	FullMatch      bool
+	Regex          bool
+	IgnoreCase     bool
+	Query          bool
+	Select         Selectors
+	Ancestors      Depth
+	Descendants    Depth
+	Siblings       bool
+	GroupByCgroup  bool
+	NSBoundaries   []string
+	PIDNamespaceOf int
+	Sort           SortOrder
+	Reverse        bool
+	CPUInterval    time.Duration
+	ShowDead       bool
+	NumericIDs     bool
+	NoIndent       bool
+	Truncate       int
+	FitTermWidth   bool
+	FitTermHeight  bool
+	Headless       bool
 }
 
 type Tree struct {
-	cfg    *Config
-	pMap   map[int]*process
-	pager  *pager.Pager
-	top    []*process
-	filter *filter
+	cfg        *Config
+	pMap       map[int]*process
+	pager      *pager.Pager
+	top        []*process
+	filter     *filter
+	weights    map[*process]int
+	comparator comparator
+	totals     map[*process]procStats
+	fdIndex    map[string][]*fdHolder
+	need       ProcConfig
+	shellPID   int
+	diffMode   bool
+
+	bootTime     time.Time
+	snapshotTime time.Time
+	pidNSChains  map[uint64][]uint64
 }
 
 func Build(cfg *Config) (*Tree, error) {
+	if err := validateNSTypes(cfg.NSBoundaries); err != nil {
+		return nil, err
+	}
+
 	t := Tree{
 		cfg: cfg,
 	}
+	t.initComparator()
 
 	if err := t.load(); err != nil {
 		return nil, err
 	}
 
+	if err := t.validatePIDNamespaceOf(); err != nil {
+		return nil, err
+	}
+
+	if t.Live() && t.loadCfg().Stats && cfg.CPUInterval > 0 {
+		if err := t.sampleCPU(); err != nil {
+			return nil, err
+		}
+	}
+
 	return &t, nil
 }
 
+func (t *Tree) Live() bool {
+	return t.cfg.Snapshot == ""
+}
+
 func (t *Tree) View() string {
 	return t.GetPager().View()
 }
@@ -86,7 +134,7 @@ func (t *Tree) HandleNewThread(ev procwatch.EventForkThread) {
 
 func (t *Tree) HandleExec(ev procwatch.EventExec) {
 	if p := t.pMap[ev.PID]; p != nil {
-		p.reload(&t.cfg.PCfg)
+		t.reloadProc(p)
 		t.refreshMatches()
 	}
 }
@@ -98,7 +146,7 @@ func (t *Tree) HandleComm(ev procwatch.EventComm) {
 	}
 
 	if ev.PID == ev.TID {
-		p.reload(&t.cfg.PCfg)
+		t.reloadProc(p)
 		t.refreshMatches()
 	} else if t.cfg.PCfg.Threads {
 		for _, thr := range p.threads {
@@ -118,14 +166,15 @@ func (t *Tree) HandleProcessExit(ev procwatch.EventExitProc) {
 	}
 
 	p.exit = &exitStatus{
-		code:   ev.ExitCode,
-		signal: ev.ExitSignal,
+		code:       ev.ExitCode,
+		signal:     ev.ExitSignal,
+		coreDumped: ev.CoreDumped || p.coreDumped,
 	}
 
 	delete(t.pMap, p.id)
 
 	for _, c := range p.children {
-		if c.reload(&t.cfg.PCfg) != nil {
+		if t.reloadProc(c) != nil {
 			continue
 		}
 
@@ -155,6 +204,51 @@ func (t *Tree) HandleThreadExit(ev procwatch.EventExitThread) {
 	}
 }
 
+// the event only carries the real and effective ids, so the rest is read from
+// the status file if it's still there
+func (t *Tree) HandleUID(ev procwatch.EventUID) {
+	if p := t.pMap[ev.PID]; p != nil && ev.PID == ev.TID {
+		p.attrs.uid = eventUGID(ev.RealUID, ev.EffectiveUID)
+		if t.loadCfg().UGID {
+			p.reloadUGIDs()
+		}
+		t.refreshMatches()
+	}
+}
+
+func (t *Tree) HandleGID(ev procwatch.EventGID) {
+	if p := t.pMap[ev.PID]; p != nil && ev.PID == ev.TID {
+		p.attrs.gid = eventUGID(ev.RealGID, ev.EffectiveGID)
+		if t.loadCfg().UGID {
+			p.reloadUGIDs()
+		}
+		t.refreshMatches()
+	}
+}
+
+func (t *Tree) HandleSID(ev procwatch.EventSID) {
+	if p := t.pMap[ev.PID]; p != nil {
+		p.attrs.sid = p.id
+		p.attrs.pgid = p.id
+		p.newSession = true
+		t.refreshMatches()
+	}
+}
+
+func (t *Tree) HandlePtrace(ev procwatch.EventPtrace) {
+	if p := t.pMap[ev.PID]; p != nil {
+		p.attrs.tracer = ev.TracerPID
+		t.refreshView()
+	}
+}
+
+func (t *Tree) HandleCoredump(ev procwatch.EventCoredump) {
+	if p := t.pMap[ev.PID]; p != nil {
+		p.coreDumped = true
+		t.refreshView()
+	}
+}
+
 func (t *Tree) ToggleShowDead() {
 	t.cfg.ShowDead = !t.cfg.ShowDead
 	t.refreshMatches()
@@ -165,6 +259,18 @@ func (t *Tree) ToggleThreads() {
 	t.reload()
 }
 
+// removeDead unlinks an exited process from its parent, whose children
+// have been reparented already
+func (t *Tree) removeDead(p *process) {
+	isP := func(c *process) bool { return c == p }
+
+	if parent := t.pMap[p.parentID]; parent != nil {
+		parent.children = slices.DeleteFunc(parent.children, isP)
+	} else {
+		t.top = slices.DeleteFunc(t.top, isP)
+	}
+}
+
 func (t *Tree) CleanupDead() {
 	for pid, p := range t.pMap {
 		p.children = slices.DeleteFunc(p.children, func(c *process) bool {
@@ -183,13 +289,34 @@ func (t *Tree) CleanupDead() {
 }
 
 func (t *Tree) refreshView() {
+	if t.cfg.Headless {
+		return
+	}
+
 	defer benchmark.Record("tree.refreshView", time.Now())
 
 	pg := t.GetPager()
 	pg.Reset()
 
+	if t.cfg.PCfg.FDs {
+		t.fdIndex = t.indexFDs()
+	}
+
+	t.totals = make(map[*process]procStats)
+	t.sumStats(t.top)
+	if t.cfg.PCfg.Stats {
+		pg.SetHeader(statsHeader(), "")
+	}
+
+	t.weights = make(map[*process]int)
 	t.sort(t.top)
-	for _, p := range t.top {
+
+	if t.cfg.GroupByCgroup {
+		t.renderCgroupGroups(pg)
+		return
+	}
+
+	for _, p := range t.visibleChildren(t.top) {
 		t.renderProcess(p, pg, 0)
 	}
 }
@@ -199,52 +326,80 @@ func (t *Tree) isProcVisible(p *process) bool {
 		return false
 	}
 
-	return t.filter == nil || t.filter.matches[p.id] != matchNone
+	if t.filter == nil {
+		return true
+	}
+
+	m := t.filter.matches[p]
+
+	return m != matchNone && m != matchPassthrough
 }
 
-func (t *Tree) sort(ps []*process) int {
-	weights := make(map[int]int)
-	totalWeight := 0
+func (t *Tree) isPassthrough(p *process) bool {
+	if p.exit != nil && !t.cfg.ShowDead {
+		return false
+	}
+
+	return t.filter != nil && t.filter.matches[p] == matchPassthrough
+}
+
+func (t *Tree) visibleChildren(ps []*process) []*process {
+	var visible []*process
+	flattened := false
 
 	for _, p := range ps {
-		if !t.isProcVisible(p) {
-			continue
+		switch {
+		case t.isProcVisible(p):
+			visible = append(visible, p)
+		case t.isPassthrough(p):
+			visible = append(visible, t.visibleChildren(p.children)...)
+			flattened = true
 		}
+	}
 
-		w := t.sort(p.children)
-		weights[p.id] = w
-		totalWeight += w + 1
+	if flattened {
+		slices.SortFunc(visible, t.compare)
 	}
 
-	slices.SortFunc(ps, func(a, b *process) int {
-		if diff := weights[a.id] - weights[b.id]; diff != 0 {
-			return diff
+	return visible
+}
+
+func (t *Tree) sort(ps []*process) int {
+	totalWeight := 0
+
+	for _, p := range ps {
+		switch {
+		case t.isProcVisible(p):
+			w := t.sort(p.children)
+			t.weights[p] = w
+			totalWeight += w + 1
+		case t.isPassthrough(p):
+			totalWeight += t.sort(p.children)
 		}
+	}
 
-		return a.id - b.id
-	})
+	slices.SortFunc(ps, t.compare)
 
 	return totalWeight
 }
 
 func (t *Tree) renderProcess(p *process, pg *pager.Pager, level int) {
-	if !t.isProcVisible(p) {
-		return
-	}
-
-	indent := strings.Repeat("  ", level)
+	indent := t.renderColumns(p) + t.indent(level)
 
 	var exit string
 	if p.exit != nil {
-		if p.exit.signal > 0 {
-			exit = fmt.Sprintf("*s:%d*", p.exit.signal)
-		} else {
-			exit = fmt.Sprintf("*e:%d*", p.exit.code)
-		}
+		exit = p.exit.String()
 	}
+	exit += p.markers() + p.diff.details()
 
 	var pid string
-	if p.attrs.nsPid == nil {
+	if t.cfg.PIDNamespaceOf > 0 {
+		if nsPid, ok := t.nsPIDView(p); ok {
+			pid = fmt.Sprintf("[%s]", nsPid)
+		} else {
+			pid = fmt.Sprintf("(%d)", p.id)
+		}
+	} else if p.attrs.nsPid == nil {
 		pid = fmt.Sprintf("[%d]", p.id)
 	} else {
 		pid = fmt.Sprint(p.attrs.nsPid)
@@ -257,26 +412,85 @@ func (t *Tree) renderProcess(p *process, pg *pager.Pager, level int) {
 
 	var ugid string
 	if t.cfg.PCfg.UGID {
-		ugid = fmt.Sprintf("[%s:%s] ", p.attrs.uid.id(), p.attrs.gid.id())
+		ugid = fmt.Sprintf("[%s] ", t.formatUGID(p))
+	}
+
+	var cgroup string
+	if t.cfg.PCfg.Cgroup {
+		cgroup = fmt.Sprintf("<%s> ", p.attrs.cgroup)
+	}
+
+	var sec string
+	if t.cfg.PCfg.Security && p.attrs.security != nil {
+		sec = fmt.Sprintf("(%s) ", p.attrs.security)
 	}
 
 	pg.WriteLine(
 		fmt.Sprintf("%s%s%s ", indent, pid, exit),
-		fmt.Sprintf("%s%s%s", ugid, workdir, p.attrs.cmdline()),
+		fmt.Sprintf("%s%s%s%s%s", ugid, sec, cgroup, workdir, p.attrs.cmdline()),
 	)
 	t.renderThreads(p, pg, indent)
 
 	if t.cfg.PCfg.FDs {
 		for _, fd := range p.fds {
-			pg.WriteLine(fmt.Sprintf("%s %d -> ", indent, fd.num), fd.link)
+			pg.WriteLine(fmt.Sprintf("%s %d -> ", indent, fd.num), t.fdDescription(p, fd))
 		}
 	}
 
-	for _, c := range p.children {
+	for _, c := range t.visibleChildren(p.children) {
+		// in grouped mode children from other units are rendered under their own group
+		if t.cfg.GroupByCgroup && cgroupUnit(c.attrs.cgroup) != cgroupUnit(p.attrs.cgroup) {
+			continue
+		}
+
+		if boundary, ok := t.renderNSBoundary(c, p); ok {
+			// keep the boundary aligned with the child below the columns
+			blank := strings.Repeat(" ", len(t.renderColumns(c)))
+			pg.WriteLine(blank+t.indent(level+1), boundary)
+		}
+
 		t.renderProcess(c, pg, level+1)
 	}
 }
 
+// renderColumns returns the per-process columns preceding the tree
+func (t *Tree) renderColumns(p *process) string {
+	var columns string
+	if t.cfg.PCfg.Stats {
+		columns += t.renderStats(p)
+	}
+	if t.diffMode {
+		columns += p.diff.marker()
+	}
+
+	return columns
+}
+
+func (t *Tree) indent(level int) string {
+	if t.cfg.NoIndent {
+		return ""
+	}
+
+	return strings.Repeat("  ", level)
+}
+
+func (t *Tree) fdDescription(p *process, fd fileDes) string {
+	link := fd.link
+	if fd.endpoint != "" {
+		link = fmt.Sprintf("%s %s", link, fd.endpoint)
+	}
+	if peers := t.fdPeers(p, fd); len(peers) > 0 {
+		descs := make([]string, len(peers))
+		for i, peer := range peers {
+			descs[i] = fmt.Sprintf("[%d] %s fd %d", peer.proc.id, peer.proc.attrs.name, peer.num)
+		}
+
+		link = fmt.Sprintf("%s => %s", link, strings.Join(descs, ", "))
+	}
+
+	return link
+}
+
 func (t *Tree) renderThreads(p *process, pg *pager.Pager, indent string) {
 	if !t.cfg.PCfg.Threads {
 		return
@@ -297,7 +511,13 @@ func (t *Tree) renderThreads(p *process, pg *pager.Pager, indent string) {
 }
 
 func (t *Tree) load() error {
-	if err := t.loadPMap(); err != nil {
+	var err error
+	if t.Live() {
+		err = t.loadPMap()
+	} else {
+		err = t.loadSnapshot()
+	}
+	if err != nil {
 		return err
 	}
 
@@ -315,12 +535,18 @@ func (t *Tree) load() error {
 func (t *Tree) loadPMap() error {
 	t.pMap = make(map[int]*process)
 
+	bootTime, err := readBootTime()
+	if err != nil {
+		return fmt.Errorf("reading boot time: %w", err)
+	}
+	t.bootTime = bootTime
+
 	for pid, err := range intDirEntries(procRoot) {
 		if err != nil {
 			return err
 		}
 
-		p, err := loadProc(pid, &t.cfg.PCfg)
+		p, err := loadProc(pid, t.loadCfg())
 		if err != nil {
 			if errors.Is(err, os.ErrNotExist) {
 				continue
@@ -333,13 +559,30 @@ func (t *Tree) loadPMap() error {
 	}
 
 	t.removeSelf()
+	t.resolveSockets(slices.Collect(maps.Values(t.pMap))...)
+
+	return nil
+}
+
+func (t *Tree) reloadProc(p *process) error {
+	if err := p.reload(t.loadCfg()); err != nil {
+		return err
+	}
+
+	t.resolveSockets(p)
 
 	return nil
 }
 
 func (t *Tree) reload() error {
+	if !t.Live() {
+		t.refreshMatches()
+		return nil
+	}
+
+	cfg := t.loadCfg()
 	for _, p := range t.pMap {
-		if err := p.reload(&t.cfg.PCfg); err != nil {
+		if err := p.reload(cfg); err != nil {
 			if errors.Is(err, os.ErrNotExist) {
 				delete(t.pMap, p.id)
 				continue
@@ -349,11 +592,34 @@ func (t *Tree) reload() error {
 		}
 	}
 
+	t.resolveSockets(slices.Collect(maps.Values(t.pMap))...)
 	t.refreshMatches()
 
 	return nil
 }
 
+func (t *Tree) loadCfg() *ProcConfig {
+	cfg := t.cfg.PCfg
+	cfg.Workdir = cfg.Workdir || t.need.Workdir
+	cfg.UGID = cfg.UGID || t.need.UGID || t.cfg.Sort == "user"
+	cfg.NamespacePID = cfg.NamespacePID || t.need.NamespacePID || t.cfg.PIDNamespaceOf > 0
+	cfg.Threads = cfg.Threads || t.need.Threads
+	cfg.FDs = cfg.FDs || t.need.FDs
+	cfg.Stats = cfg.Stats || t.need.Stats || t.cfg.Sort == "cpu" || t.cfg.Sort == "rss"
+	cfg.Cgroup = cfg.Cgroup || t.need.Cgroup || t.cfg.GroupByCgroup
+	cfg.Security = cfg.Security || t.need.Security
+	cfg.Namespaces = cfg.Namespaces || t.need.Namespaces || len(t.cfg.NSBoundaries) > 0 || t.cfg.PIDNamespaceOf > 0
+
+	return &cfg
+}
+
+func (t *Tree) require(need ProcConfig) bool {
+	old := *t.loadCfg()
+	t.need = need
+
+	return *t.loadCfg() != old
+}
+
 func (t *Tree) removeSelf() {
 	p := t.pMap[os.Getpid()]
 	if p == nil {
@@ -362,9 +628,14 @@ func (t *Tree) removeSelf() {
 
 	delete(t.pMap, p.id)
 
-	for parent := t.pMap[p.parentID]; isSudoAncestor(parent, p); parent = t.pMap[parent.parentID] {
+	parent := t.pMap[p.parentID]
+	for ; isSudoAncestor(parent, p); parent = t.pMap[parent.parentID] {
 		delete(t.pMap, parent.id)
 	}
+
+	if parent != nil {
+		t.shellPID = parent.id
+	}
 }
 
 func isSudoAncestor(ancestor, descendant *process) bool {
@@ -8,12 +8,27 @@ import (
 
 type ugid interface {
 	id() string
+	format(name func(int) string) string
+	effectiveID() int
+	mismatch() bool
 }
 
 type scalarUGID int
 
 func (s scalarUGID) id() string {
-	return strconv.Itoa(int(s))
+	return s.format(strconv.Itoa)
+}
+
+func (s scalarUGID) format(name func(int) string) string {
+	return name(int(s))
+}
+
+func (s scalarUGID) effectiveID() int {
+	return int(s)
+}
+
+func (s scalarUGID) mismatch() bool {
+	return false
 }
 
 type multiUGID struct {
@@ -24,7 +39,19 @@ type multiUGID struct {
 }
 
 func (m multiUGID) id() string {
-	return fmt.Sprintf("(r:%d e:%d ss:%d fs:%d)", m.real, m.effective, m.savedSet, m.filesystem)
+	return m.format(strconv.Itoa)
+}
+
+func (m multiUGID) format(name func(int) string) string {
+	return fmt.Sprintf("(r:%s e:%s ss:%s fs:%s)", name(m.real), name(m.effective), name(m.savedSet), name(m.filesystem))
+}
+
+func (m multiUGID) effectiveID() int {
+	return m.effective
+}
+
+func (m multiUGID) mismatch() bool {
+	return m.real != m.effective
 }
 
 func parseUGID(raw string) (_ ugid, err error) {
@@ -55,6 +82,78 @@ func parseUGID(raw string) (_ ugid, err error) {
 	}, nil
 }
 
+func eventUGID(real, effective int) ugid {
+	if real == effective {
+		return scalarUGID(real)
+	}
+
+	// the saved set and the filesystem ids aren't reported by the event
+	return multiUGID{
+		real:       real,
+		effective:  effective,
+		savedSet:   effective,
+		filesystem: effective,
+	}
+}
+
+func parseGroups(raw string) ([]int, error) {
+	fields := strings.Fields(raw)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	groups := make([]int, len(fields))
+	for i, f := range fields {
+		gid, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid supplementary group %q: %w", f, err)
+		}
+
+		groups[i] = gid
+	}
+
+	return groups, nil
+}
+
+// idNamers resolves ids through the local databases only for a live tree, as
+// a snapshot may come from a host with different users
+func (t *Tree) idNamers() (userName, groupName func(int) string) {
+	if t.cfg.NumericIDs || !t.Live() {
+		return strconv.Itoa, strconv.Itoa
+	}
+
+	return lookupUserName, lookupGroupName
+}
+
+func (t *Tree) formatUGID(p *process) string {
+	userName, groupName := t.idNamers()
+
+	if p.attrs.uid == nil || p.attrs.gid == nil {
+		return "?:?"
+	}
+
+	s := p.attrs.uid.format(userName)
+	if p.attrs.uid.mismatch() {
+		s += "*setuid*"
+	}
+
+	s += ":" + p.attrs.gid.format(groupName)
+	if p.attrs.gid.mismatch() {
+		s += "*setgid*"
+	}
+
+	if len(p.attrs.groups) > 0 {
+		names := make([]string, len(p.attrs.groups))
+		for i, gid := range p.attrs.groups {
+			names[i] = groupName(gid)
+		}
+
+		s += " +" + strings.Join(names, ",")
+	}
+
+	return s
+}
+
 const (
 	ugidFieldsCount = 4
 )
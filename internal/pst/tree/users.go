@@ -0,0 +1,103 @@
+package tree
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// idDB is a parsed passwd(5) or group(5) file; both keep the name and the
+// numeric id in the first and the third fields
+type idDB struct {
+	names map[int]string
+	ids   map[string]int
+	err   error
+}
+
+var (
+	passwdDB = sync.OnceValue(func() *idDB { return loadIDDB(passwdPath) })
+	groupDB  = sync.OnceValue(func() *idDB { return loadIDDB(groupPath) })
+)
+
+func loadIDDB(path string) *idDB {
+	db := idDB{
+		names: make(map[int]string),
+		ids:   make(map[string]int),
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		db.err = err
+		return &db
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		id, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+
+		// the first entry wins, like in getpwuid/getgrgid
+		if _, ok := db.names[id]; !ok {
+			db.names[id] = fields[0]
+		}
+		if _, ok := db.ids[fields[0]]; !ok {
+			db.ids[fields[0]] = id
+		}
+	}
+
+	db.err = scanner.Err()
+
+	return &db
+}
+
+func (db *idDB) lookupID(kind, name string) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+
+	if id, ok := db.ids[name]; ok {
+		return id, nil
+	}
+
+	if db.err != nil {
+		return 0, db.err
+	}
+
+	return 0, fmt.Errorf("unknown %s %q", kind, name)
+}
+
+func (db *idDB) lookupName(id int) string {
+	if name, ok := db.names[id]; ok {
+		return name
+	}
+
+	return strconv.Itoa(id)
+}
+
+func lookupUser(name string) (int, error) {
+	return passwdDB().lookupID("user", name)
+}
+
+func lookupUserName(uid int) string {
+	return passwdDB().lookupName(uid)
+}
+
+func lookupGroupName(gid int) string {
+	return groupDB().lookupName(gid)
+}
+
+const (
+	passwdPath = "/etc/passwd"
+	groupPath  = "/etc/group"
+)
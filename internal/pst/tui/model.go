@@ -15,12 +15,16 @@ import (
 
 type Config struct {
 	Fullscreen bool
+	Refresh    time.Duration
 }
 
 func Run(cfg *Config, pst *tree.Tree) error {
-	watcher, err := procwatch.Watch()
-	if err != nil {
-		return err
+	watcher := procwatch.Idle()
+	if pst.Live() {
+		var err error
+		if watcher, err = procwatch.Watch(); err != nil {
+			return err
+		}
 	}
 
 	var opts []tea.ProgramOption
@@ -53,10 +57,11 @@ type tui struct {
 	width    int
 	height   int
 	quitting bool
+	ticking  bool
 }
 
 func (t *tui) Init() tea.Cmd {
-	return t.recvMsg
+	return tea.Batch(t.recvMsg, t.tick())
 }
 
 func (t *tui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -65,8 +70,16 @@ func (t *tui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
+	case tickMsg:
+		// ticks have their own loop, so don't issue another recvMsg for them
+		t.pst.RefreshStats()
+		return t, t.tick()
 	case tea.KeyMsg:
 		cmd = t.handleKey(msg)
+		if !t.ticking {
+			// the sort order may have switched to one relying on stats
+			return t, tea.Batch(tea.Sequence(cmd, t.recvMsg), t.tick())
+		}
 	case tea.WindowSizeMsg:
 		t.handleWinSize(msg)
 	case procMsg:
@@ -84,6 +97,19 @@ func (t *tui) View() string {
 	return t.pst.View() + "\n"
 }
 
+type tickMsg time.Time
+
+func (t *tui) tick() tea.Cmd {
+	t.ticking = t.cfg.Refresh > 0 && t.pst.SamplesStats() && !t.quitting
+	if !t.ticking {
+		return nil
+	}
+
+	return tea.Tick(t.cfg.Refresh, func(tm time.Time) tea.Msg {
+		return tickMsg(tm)
+	})
+}
+
 type procMsg struct {
 	event any
 	err   error
@@ -113,20 +139,7 @@ func (t *tui) handleProcMsg(msg procMsg) tea.Cmd {
 		return tea.Sequence(cmd, tea.Quit)
 	}
 
-	switch ev := msg.event.(type) {
-	case procwatch.EventForkProc:
-		t.pst.HandleNewProcess(ev)
-	case procwatch.EventForkThread:
-		t.pst.HandleNewThread(ev)
-	case procwatch.EventExec:
-		t.pst.HandleExec(ev)
-	case procwatch.EventComm:
-		t.pst.HandleComm(ev)
-	case procwatch.EventExitProc:
-		t.pst.HandleProcessExit(ev)
-	case procwatch.EventExitThread:
-		t.pst.HandleThreadExit(ev)
-	}
+	t.pst.HandleEvent(msg.event)
 
 	return nil
 }
@@ -143,6 +156,10 @@ func (t *tui) handleKey(msg tea.KeyMsg) tea.Cmd {
 		t.pst.CleanupDead()
 	case "t":
 		t.pst.ToggleThreads()
+	case "s":
+		t.pst.CycleSort()
+	case "S":
+		t.pst.ToggleReverse()
 	case "f":
 		cmd = t.toggleFullscreen()
 	case "r":
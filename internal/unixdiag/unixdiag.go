@@ -0,0 +1,127 @@
+package unixdiag
+
+/*
+#include <linux/netlink.h>
+#include <linux/sock_diag.h>
+#include <linux/unix_diag.h>
+*/
+import "C"
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func Peers() (_ map[uint64]uint64, err error) {
+	sock, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM, unix.NETLINK_SOCK_DIAG)
+	if err != nil {
+		return nil, fmt.Errorf("creating netlink socket: %w", err)
+	}
+	defer func() {
+		if ce := unix.Close(sock); ce != nil {
+			err = errors.Join(err, fmt.Errorf("closing netlink socket: %w", ce))
+		}
+	}()
+
+	if err := sendDumpRequest(sock); err != nil {
+		return nil, err
+	}
+
+	peers := make(map[uint64]uint64)
+	buf := make([]byte, recvBufSize)
+
+	for {
+		n, _, err := unix.Recvfrom(sock, buf, 0)
+		if err != nil {
+			return nil, fmt.Errorf("receiving from nl socket: %w", err)
+		}
+
+		nlmessages, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return nil, fmt.Errorf("parsing netlink message: %w", err)
+		}
+
+		for _, nlmsg := range nlmessages {
+			switch nlmsg.Header.Type {
+			case unix.NLMSG_DONE:
+				return peers, nil
+			case unix.NLMSG_ERROR:
+				return nil, fmt.Errorf("sock_diag error: 0x%x", nlmsg.Data)
+			case unix.SOCK_DIAG_BY_FAMILY:
+				if ino, peer, ok := parseDiagMsg(nlmsg.Data); ok {
+					peers[ino] = peer
+				}
+			}
+		}
+	}
+}
+
+func sendDumpRequest(sock int) error {
+	header := unix.NlMsghdr{
+		Type:  unix.SOCK_DIAG_BY_FAMILY,
+		Flags: unix.NLM_F_REQUEST | unix.NLM_F_DUMP,
+		Pid:   uint32(os.Getpid()),
+	}
+	header.Len = uint32(C.sizeof_struct_nlmsghdr + C.sizeof_struct_unix_diag_req)
+
+	req := C.struct_unix_diag_req{
+		sdiag_family: unix.AF_UNIX,
+		udiag_states: ^C.__u32(0),
+		udiag_show:   C.UDIAG_SHOW_PEER,
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, header.Len))
+	binary.Write(buf, binary.LittleEndian, header)
+	binary.Write(buf, binary.LittleEndian, req)
+
+	destAddr := &unix.SockaddrNetlink{
+		Family: unix.AF_NETLINK,
+		Pid:    0, // 0 is the kernel
+	}
+
+	if err := unix.Sendto(sock, buf.Bytes(), 0, destAddr); err != nil {
+		return fmt.Errorf("sending sock_diag request: %w", err)
+	}
+
+	return nil
+}
+
+func parseDiagMsg(data []byte) (ino, peer uint64, ok bool) {
+	if len(data) < C.sizeof_struct_unix_diag_msg {
+		return 0, 0, false
+	}
+
+	msg := (*C.struct_unix_diag_msg)(unsafe.Pointer(&data[0]))
+	attrs := data[C.sizeof_struct_unix_diag_msg:]
+
+	for len(attrs) >= unix.SizeofRtAttr {
+		rta := (*unix.RtAttr)(unsafe.Pointer(&attrs[0]))
+		if int(rta.Len) < unix.SizeofRtAttr || int(rta.Len) > len(attrs) {
+			break
+		}
+
+		if rta.Type == C.UNIX_DIAG_PEER && int(rta.Len) >= unix.SizeofRtAttr+4 {
+			peer := binary.NativeEndian.Uint32(attrs[unix.SizeofRtAttr:])
+			return uint64(msg.udiag_ino), uint64(peer), peer != 0
+		}
+
+		attrs = attrs[min(rtaAlign(int(rta.Len)), len(attrs)):]
+	}
+
+	return 0, 0, false
+}
+
+func rtaAlign(n int) int {
+	return (n + unix.RTA_ALIGNTO - 1) &^ (unix.RTA_ALIGNTO - 1)
+}
+
+const (
+	recvBufSize = 1 << 16
+)